@@ -0,0 +1,73 @@
+package hareru_cq
+
+import (
+	"context"
+	"encoding/json"
+
+	uuid "github.com/satori/go.uuid"
+	"github.com/tidwall/gjson"
+)
+
+// CallAction 调用任意 Action
+// params 按 JSON 序列化作为请求参数 (可为带 json tag 的结构体或 map), 响应的 data 解码为 Resp
+// 失败响应 (status 非 ok/async) 返回 ActionFailErr
+//
+//	info, err := CallAction[GroupInfo](ctx, bot, "get_group_info", map[string]any{"group_id": 123})
+func CallAction[Resp any](ctx context.Context, bot *Bot, action string, params any) (Resp, error) {
+	var resp Resp
+
+	res, err := bot.call(ctx, action, params)
+	if err != nil {
+		return resp, err
+	}
+
+	data := res.Json.Get("data")
+	if !data.Exists() || data.Type == gjson.Null {
+		return resp, nil
+	}
+
+	err = json.Unmarshal([]byte(data.Raw), &resp)
+	if err != nil {
+		return resp, &ActionFailErr{
+			Action:  action,
+			RetCode: res.RetCode,
+			Message: "响应解析失败: " + err.Error(),
+		}
+	}
+
+	return resp, nil
+}
+
+// call 调用 Action, 失败响应转换为 ActionFailErr
+func (bot *Bot) call(ctx context.Context, action string, params any) (*CqResponse, error) {
+	req := CqRequest{
+		Action: action,
+		Params: params,
+		Echo:   uuid.NewV4().String(),
+	}
+
+	res, err := bot.doActionCtx(ctx, &req)
+	if err != nil {
+		return nil, err
+	}
+
+	if res.Status != "ok" && res.Status != "async" {
+		return nil, newActionFailErr(action, res)
+	}
+
+	return res, nil
+}
+
+func newActionFailErr(action string, res *CqResponse) *ActionFailErr {
+	message := res.Wording
+	if message == "" {
+		message = res.Msg
+	}
+
+	return &ActionFailErr{
+		Action:  action,
+		RetCode: res.RetCode,
+		Wording: res.Wording,
+		Message: message,
+	}
+}
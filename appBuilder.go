@@ -1,21 +1,128 @@
 package hareru_cq
 
+import (
+	"time"
+
+	log "github.com/sirupsen/logrus"
+)
+
 type ApplicationBuilder struct {
 	Name    string
 	Client  *Client
 	Bot     *Bot
 	Updater *Updater
+
+	mode       ClientMode
+	universal  bool
+	listenAddr string
+	httpUrl    string
+	secret     string
+	token      string
+
+	actionTimeout        time.Duration
+	messageFormat        string
+	superusers           []int64
+	embedLocalFiles      bool
+	reconnectInterval    time.Duration
+	maxReconnectInterval time.Duration
 }
 
 func NewApplicationBuilder() *ApplicationBuilder {
 	return &ApplicationBuilder{}
 }
 
+// Universal 正向 WebSocket 使用通用端点 (/), API 与事件共用一个连接
+func (builder *ApplicationBuilder) Universal() *ApplicationBuilder {
+	builder.mode = ForwardWsMode
+	builder.universal = true
+	return builder
+}
+
+// ReverseWebSocket 使用反向 WebSocket, 在 listenAddr 上等待 cqhttp 连接
+// 此时 Build 的 apiUrl 参数将被忽略
+func (builder *ApplicationBuilder) ReverseWebSocket(listenAddr string) *ApplicationBuilder {
+	builder.mode = ReverseWsMode
+	builder.listenAddr = listenAddr
+	return builder
+}
+
+// Http 使用 HTTP API 调用 Action, 并在 listenAddr 上接收 HTTP POST 上报
+// 此时 Build 的 apiUrl 参数将被忽略
+func (builder *ApplicationBuilder) Http(httpUrl string, listenAddr string) *ApplicationBuilder {
+	builder.mode = HttpMode
+	builder.httpUrl = httpUrl
+	builder.listenAddr = listenAddr
+	return builder
+}
+
+// Secret 设置 HTTP POST 上报的签名密钥, 与 cqhttp 配置中的 secret 一致
+func (builder *ApplicationBuilder) Secret(secret string) *ApplicationBuilder {
+	builder.secret = secret
+	return builder
+}
+
+// AccessToken 设置 AccessToken, 与 cqhttp 配置中的 access-token 一致
+func (builder *ApplicationBuilder) AccessToken(token string) *ApplicationBuilder {
+	builder.token = token
+	return builder
+}
+
+// Reconnect 设置断线重连的首次间隔与最大间隔
+func (builder *ApplicationBuilder) Reconnect(interval time.Duration, maxInterval time.Duration) *ApplicationBuilder {
+	builder.reconnectInterval = interval
+	builder.maxReconnectInterval = maxInterval
+	return builder
+}
+
+// ActionTimeout 设置 Action 的默认超时, 小于 0 时不超时
+func (builder *ApplicationBuilder) ActionTimeout(timeout time.Duration) *ApplicationBuilder {
+	builder.actionTimeout = timeout
+	return builder
+}
+
+// MessageFormat 设置发送消息的格式, StringMessageFormat 或 ArrayMessageFormat
+func (builder *ApplicationBuilder) MessageFormat(format string) *ApplicationBuilder {
+	builder.messageFormat = format
+	return builder
+}
+
+// Superusers 设置超级用户, 供 filters.Superuser 等使用
+func (builder *ApplicationBuilder) Superusers(userIds ...int64) *ApplicationBuilder {
+	builder.superusers = append(builder.superusers, userIds...)
+	return builder
+}
+
+// EmbedLocalFiles 发送前将本地媒体文件读取为 base64://, 用于 cqhttp 在另一主机时
+func (builder *ApplicationBuilder) EmbedLocalFiles() *ApplicationBuilder {
+	builder.embedLocalFiles = true
+	return builder
+}
+
 func (builder *ApplicationBuilder) Build(appName string, apiUrl string) *Application {
 	builder.Name = appName
-	builder.Client = NewClient(apiUrl, "")
+	switch builder.mode {
+	case ReverseWsMode:
+		builder.Client = NewReverseClient(builder.listenAddr, builder.token)
+	case HttpMode:
+		builder.Client = NewHttpClient(builder.httpUrl, builder.listenAddr, builder.token)
+		builder.Client.Secret = builder.secret
+	default:
+		builder.Client = NewClient(apiUrl, builder.token)
+		builder.Client.Universal = builder.universal
+	}
+	if builder.reconnectInterval > 0 {
+		builder.Client.ReconnectInterval = builder.reconnectInterval
+	}
+	if builder.maxReconnectInterval > 0 {
+		builder.Client.MaxReconnectInterval = builder.maxReconnectInterval
+	}
+
 	builder.Bot = &Bot{
-		Client: builder.Client,
+		Client:          builder.Client,
+		ActionTimeout:   builder.actionTimeout,
+		MessageFormat:   builder.messageFormat,
+		Superusers:      builder.superusers,
+		EmbedLocalFiles: builder.embedLocalFiles,
 	}
 	builder.Updater = &Updater{
 		Updates: make(chan *Update, 100),
@@ -31,6 +138,7 @@ func (builder *ApplicationBuilder) Build(appName string, apiUrl string) *Applica
 
 	err := app.Init()
 	if err != nil {
+		log.Error(appName, " 初始化失败: ", err)
 		return nil
 	}
 
@@ -4,6 +4,7 @@ import (
 	log "github.com/sirupsen/logrus"
 	"os"
 	"os/signal"
+	"sync"
 	"syscall"
 )
 
@@ -78,15 +79,37 @@ func (app *Application) processUpdate() {
 
 	for {
 		update := <-app.Updater.Updates
+		app.dispatch(update)
+	}
+}
+
+// dispatch 将 Update 分发给匹配的 Handler, 并执行 Handler 返回的快速操作
+func (app *Application) dispatch(update *Update) {
+	var wg sync.WaitGroup
+
+	for _, handler := range app.Handlers {
+		check := handler.CheckUpdate(update)
+		if check {
+			handler.CollectArgs(update)
 
-		for _, handler := range app.Handlers {
-			check := handler.CheckUpdate(update)
-			if check {
-				handler.CollectArgs(update)
-				go handler.HandleUpdate(update)
-			}
+			wg.Add(1)
+			go func(handler Handler) {
+				defer wg.Done()
+
+				result := handler.HandleUpdate(update)
+				if op := quickOperationOf(result); op != nil {
+					update.handleQuickOperation(op)
+				}
+			}(handler)
 		}
 	}
+
+	if update.quickReply != nil {
+		go func() {
+			wg.Wait()
+			update.handleQuickOperation(nil)
+		}()
+	}
 }
 
 func (app *Application) exitHandler() {
@@ -0,0 +1,73 @@
+package hareru_cq
+
+import (
+	"crypto/subtle"
+	"net/http"
+	"strings"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// authHeader 携带 AccessToken 的请求头
+func (c *Client) authHeader() http.Header {
+	header := http.Header{}
+	if c.EnableAccessToken {
+		header.Set("Authorization", "Bearer "+c.AccessToken)
+	}
+	return header
+}
+
+// authorize 校验传入请求的 AccessToken, 校验失败时写入 401/403
+// required 为 false 时, 仅在请求携带了 AccessToken 时校验
+func (c *Client) authorize(w http.ResponseWriter, r *http.Request, required bool) bool {
+	if !c.EnableAccessToken {
+		return true
+	}
+
+	token := requestToken(r)
+	if token == "" {
+		if !required {
+			return true
+		}
+
+		log.Warnln("请求未携带 AccessToken, 已拒绝:", r.RemoteAddr)
+		http.Error(w, "access token required", http.StatusUnauthorized)
+		return false
+	}
+
+	if subtle.ConstantTimeCompare([]byte(token), []byte(c.AccessToken)) != 1 {
+		log.Warnln("AccessToken 不匹配, 已拒绝:", r.RemoteAddr)
+		http.Error(w, "access token mismatch", http.StatusForbidden)
+		return false
+	}
+
+	return true
+}
+
+// requestToken 取 Authorization 头 (Bearer / Token) 或 access_token 参数
+func requestToken(r *http.Request) string {
+	authorization := r.Header.Get("Authorization")
+	for _, scheme := range []string{"Bearer ", "Token "} {
+		if token, found := strings.CutPrefix(authorization, scheme); found {
+			return strings.TrimSpace(token)
+		}
+	}
+
+	return r.URL.Query().Get("access_token")
+}
+
+// checkAuthResponse 将 401/403 响应转换为 AuthFailedErr
+func checkAuthResponse(response *http.Response) error {
+	if response == nil {
+		return nil
+	}
+
+	if response.StatusCode == http.StatusUnauthorized || response.StatusCode == http.StatusForbidden {
+		return &AuthFailedErr{
+			StatusCode: response.StatusCode,
+			Message:    response.Status,
+		}
+	}
+
+	return nil
+}
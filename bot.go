@@ -2,13 +2,15 @@ package hareru_cq
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
-	"github.com/gorilla/websocket"
-	uuid "github.com/satori/go.uuid"
 	log "github.com/sirupsen/logrus"
 	"github.com/tidwall/gjson"
 	"image"
+	"sync/atomic"
+	"time"
 )
 
 const (
@@ -26,6 +28,14 @@ const (
 	FriendAddEvent     = "friend_add"
 	GroupCardEvent     = "group_card"
 	EssenceEvent       = "essence"
+	ClientStatusEvent  = "client_status" // go-cqhttp 其他客户端在线状态变更
+	OfflineFileEvent   = "offline_file"  // go-cqhttp 接收到离线文件
+
+	NotifyEvent    = "notify" // NotifyEvents, 匹配所有 notify 子类型
+	PokeEvent      = "poke"
+	LuckyKingEvent = "lucky_king"
+	HonorEvent     = "honor"
+	TitleEvent     = "title"
 
 	FriendRequestEvent = "friend_request" //RequestEvents
 	GroupRequestEvent  = "group_request"
@@ -33,44 +43,175 @@ const (
 	LifecycleEvent = "lifecycle" //MetaEvents
 	HeartbeatEvent = "heartbeat"
 
+	ConnectionMetaEvent = "connection"  // 由 hareru_cq 生成的连接状态元事件
+	ConnectEvent        = "connect"     // 重连成功
+	DisconnectEvent     = "disconnect"  // 连接断开
+	AuthFailedEvent     = "auth_failed" // 重连时 AccessToken 被拒绝, 已停止重连, 在 DisconnectEvent 之后发生
+
 	GroupOwnerRole  = "owner"
 	GroupAdminRole  = "admin"
 	GroupMemberRole = "member"
 )
 
+const (
+	GroupMessageType   = "group" // 消息类型
+	PrivateMessageType = "private"
+)
+
+const (
+	StringMessageFormat = "string" // 消息格式: CQ 码字符串
+	ArrayMessageFormat  = "array"  // 消息格式: 消息段数组
+)
+
+// DefaultActionTimeout 默认 Action 超时
+const DefaultActionTimeout = 30 * time.Second
+
 type Bot struct {
 	Client *Client
 
-	Info        *BotInfo
-	ResChan     map[string]chan *CqResponse
+	info            atomic.Pointer[BotInfo]
+	ActionTimeout   time.Duration // 默认 Action 超时, 为 0 时使用 DefaultActionTimeout, 小于 0 时不超时
+	MessageFormat   string        // 发送消息的格式, StringMessageFormat 或 ArrayMessageFormat, 为空时 string 原样发送, 消息链以数组发送
+	Superusers      []int64       // 超级用户 QQ
+	EmbedLocalFiles bool          // 发送前将 file:/// 媒体文件读取为 base64://, 用于 cqhttp 在另一主机时, 如经 NAT 的反向 WebSocket
+
+	pending     *responseRegistry
 	initialized bool
+	started     bool // 连接钩子与响应分发已启动, Stop 后再次 Init 时不重复启动
 }
 
 // BotInfo bot信息
 type BotInfo struct {
-	UserId   int64  //QQ
-	NickName string //昵称
+	UserId   int64  `json:"user_id"`  //QQ
+	NickName string `json:"nickname"` //昵称
 }
 
-func (bot *Bot) doAction(req *CqRequest) error {
-	err := bot.Client.ActConn.WriteJSON(req)
+// Info Bot 信息, 重连后刷新, 未初始化时为 nil
+func (bot *Bot) Info() *BotInfo {
+	return bot.info.Load()
+}
+
+// IsSuperuser 用户是否为超级用户
+func (bot *Bot) IsSuperuser(userId int64) bool {
+	for _, id := range bot.Superusers {
+		if id == userId {
+			return true
+		}
+	}
+	return false
+}
+
+// doAction 发送请求并等待响应
+func (bot *Bot) doAction(req *CqRequest) (*CqResponse, error) {
+	return bot.doActionCtx(context.Background(), req)
+}
+
+// doActionCtx 发送请求并等待响应, ctx 未设置截止时间时使用 Bot 的默认超时
+func (bot *Bot) doActionCtx(ctx context.Context, req *CqRequest) (*CqResponse, error) {
+	ctx, cancel := bot.actionContext(ctx)
+	defer cancel()
+
+	if bot.Client.Mode == HttpMode {
+		res, err := bot.Client.postAction(ctx, req)
+		if err != nil {
+			err = actionCtxErr(ctx, req.Action, err)
+			log.Error("发送请求失败:", err)
+			return nil, err
+		}
+		return res, nil
+	}
+
+	resChan := bot.pending.register(req.Echo)
+	defer bot.pending.cancel(req.Echo)
+
+	err := bot.Client.writeRequest(ctx, req)
 	if err != nil {
+		err = actionCtxErr(ctx, req.Action, err)
 		log.Error("发送请求失败:", err)
-		return err
+		return nil, err
+	}
+
+	select {
+	case res, ok := <-resChan:
+		if !ok {
+			return nil, &ConnectionLostErr{"等待响应时连接断开"}
+		}
+		return res, nil
+	case <-ctx.Done():
+		return nil, actionCtxErr(ctx, req.Action, ctx.Err())
 	}
-	return nil
 }
 
-func (bot *Bot) getActionResult(echo string) *CqResponse {
-	resChan := make(chan *CqResponse)
+// actionContext 为未设置截止时间的 ctx 附加默认超时
+func (bot *Bot) actionContext(ctx context.Context) (context.Context, context.CancelFunc) {
+	if _, ok := ctx.Deadline(); ok {
+		return context.WithCancel(ctx)
+	}
 
-	bot.ResChan[echo] = resChan
+	timeout := bot.ActionTimeout
+	if timeout == 0 {
+		timeout = DefaultActionTimeout
+	}
+	if timeout < 0 {
+		return context.WithCancel(ctx)
+	}
 
-	res := <-resChan
+	return context.WithTimeout(ctx, timeout)
+}
 
-	delete(bot.ResChan, echo)
-	bot.ResChan[echo] = nil
-	return res
+// actionCtxErr ctx 超时时返回 ActionTimeoutErr, 否则原样返回 err
+func actionCtxErr(ctx context.Context, action string, err error) error {
+	if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		return &ActionTimeoutErr{Action: action}
+	}
+	return err
+}
+
+// onDisconnect 连接断开
+func (bot *Bot) onDisconnect(err error) {
+	bot.pending.failAll()
+	bot.emitConnectionEvent(DisconnectEvent, err)
+}
+
+// onAuthFailed 重连被拒绝, 此前已通知断开
+func (bot *Bot) onAuthFailed(err error) {
+	bot.emitConnectionEvent(AuthFailedEvent, err)
+}
+
+// onConnect 重连成功后刷新 Bot 信息
+func (bot *Bot) onConnect() {
+	botInfo, err := bot.getBotInfo()
+	if err != nil {
+		log.Error("获取Bot信息失败:", err)
+	} else {
+		bot.info.Store(botInfo)
+	}
+
+	bot.emitConnectionEvent(ConnectEvent, nil)
+}
+
+// emitConnectionEvent 生成连接状态元事件, 与 cqhttp 上报的事件一同分发给 Handler
+func (bot *Bot) emitConnectionEvent(subType string, cause error) {
+	event := map[string]any{
+		"time":            time.Now().Unix(),
+		"post_type":       "meta_event",
+		"meta_event_type": ConnectionMetaEvent,
+		"sub_type":        subType,
+	}
+	if info := bot.Info(); info != nil {
+		event["self_id"] = info.UserId
+	}
+	if cause != nil {
+		event["error"] = cause.Error()
+	}
+
+	data, err := json.Marshal(event)
+	if err != nil {
+		log.Error("连接事件生成失败:", err)
+		return
+	}
+
+	bot.Client.queueEvent(&eventFrame{data: data})
 }
 
 func (bot *Bot) Stop() {
@@ -86,20 +227,25 @@ func (bot *Bot) Init() error {
 		}
 	}
 
+	if !bot.started {
+		bot.pending = newResponseRegistry()
+		bot.Client.onConnect = append(bot.Client.onConnect, bot.onConnect)
+		bot.Client.onDisconnect = append(bot.Client.onDisconnect, bot.onDisconnect)
+		bot.Client.onAuthFailed = append(bot.Client.onAuthFailed, bot.onAuthFailed)
+		bot.ResponseUpdater()
+		bot.started = true
+	}
+
 	botInfo, err := bot.getBotInfo()
 	if err != nil {
-		log.Fatal("获取Bot信息失败:", err)
+		log.Error("获取Bot信息失败:", err)
 		return err
 	}
 
-	bot.Info = botInfo
-	bot.ResChan = make(map[string]chan *CqResponse)
-
-	go bot.ResponseUpdater()
-
+	bot.info.Store(botInfo)
 	bot.initialized = true
 
-	log.Infof("Bot Info: %s(%d)", bot.Info.NickName, bot.Info.UserId)
+	log.Infof("Bot Info: %s(%d)", botInfo.NickName, botInfo.UserId)
 
 	return nil
 }
@@ -110,157 +256,181 @@ func (bot *Bot) IsInitialized() bool {
 
 // getBotInfo 获取Bot信息
 func (bot *Bot) getBotInfo() (*BotInfo, error) {
-	req := CqRequest{
-		Action: "get_login_info",
-	}
-
-	reqJson, err := json.Marshal(req)
-	if err != nil {
-		return nil, err
-	}
-
-	err = bot.Client.ActConn.WriteMessage(websocket.TextMessage, reqJson)
-	if err != nil {
-		return nil, err
-	}
-
-	_, message, err := bot.Client.ActConn.ReadMessage()
-	if err != nil {
-		return nil, err
-	}
-
-	res := CqResponse{}
-	err = json.Unmarshal(message, &res)
+	botInfo, err := CallAction[BotInfo](context.Background(), bot, "get_login_info", nil)
 	if err != nil {
 		return nil, err
 	}
 
-	if res.Status != "ok" {
-		return nil, &ActionFailErr{res.Wording}
-	}
-
-	res.Json = gjson.ParseBytes(message)
-
-	botInfo := BotInfo{
-		UserId:   res.Json.Get("data.user_id").Int(),
-		NickName: res.Json.Get("data.nickname").String(),
-	}
-
 	return &botInfo, nil
 }
 
 func (bot *Bot) ResponseUpdater() {
 	//Handler
 	go func(bot *Bot) {
-		for {
-			_, res, err := bot.Client.ActConn.ReadMessage()
-
-			if err != nil {
-				log.Fatal("与 cqHttp 的连接出错:", err)
-				return
-			}
-
+		for res := range bot.Client.responses {
 			cqRes := &CqResponse{}
-			err = json.Unmarshal(res, &cqRes)
+			err := json.Unmarshal(res, &cqRes)
 			if err != nil {
-				log.Fatal("cqHttp 事件获取失败:", err)
-				return
+				log.Error("cqHttp 响应解析失败:", err)
+				continue
 			}
 
 			cqRes.Json = gjson.ParseBytes(res)
 
-			if bot.ResChan[cqRes.Echo] != nil {
-				bot.ResChan[cqRes.Echo] <- cqRes
+			if !bot.pending.resolve(cqRes) {
+				log.Debugln("忽略未知 echo 的响应:", cqRes.Echo)
 			}
 		}
 	}(bot)
 }
 
 // SendPrivateMessage 发送私聊信息
-// message string 消息文本
+// message any 消息内容, 可为 string (CQ 码), MessageChain, *MessageBuilder 或 Segment
 // id int64 用户 ID
-// autoEscape bool 消息内容是否作为纯文本发送 ( 即不解析 CQ 码 )
-func (bot *Bot) SendPrivateMessage(message string, userId int64, autoEscape bool) error {
-	req := CqRequest{
-		Action: "send_private_msg",
-		Params: map[string]interface{}{
-			"message":     message,
-			"user_id":     userId,
-			"auto_escape": autoEscape,
-		},
-		Echo: uuid.NewV4().String(),
-	}
+// autoEscape bool 消息内容是否作为纯文本发送 ( 即不解析 CQ 码 ), 仅对 string 有效
+func (bot *Bot) SendPrivateMessage(message any, userId int64, autoEscape bool) (*SentMessage, error) {
+	return bot.SendPrivateMessageCtx(context.Background(), message, userId, autoEscape)
+}
 
-	err := bot.doAction(&req)
+// SendPrivateMessageCtx 发送私聊信息
+func (bot *Bot) SendPrivateMessageCtx(ctx context.Context, message any, userId int64, autoEscape bool) (*SentMessage, error) {
+	return bot.sendMessage(ctx, PrivateMessageType, userId, message, autoEscape)
+}
+
+// SendGroupMessage 发送群聊信息
+// message any 消息内容, 同 SendPrivateMessage
+// id int64 群组 ID
+func (bot *Bot) SendGroupMessage(message any, groupId int64, autoEscape bool) (*SentMessage, error) {
+	return bot.SendGroupMessageCtx(context.Background(), message, groupId, autoEscape)
+}
+
+// SendGroupMessageCtx 发送群聊信息
+func (bot *Bot) SendGroupMessageCtx(ctx context.Context, message any, groupId int64, autoEscape bool) (*SentMessage, error) {
+	return bot.sendMessage(ctx, GroupMessageType, groupId, message, autoEscape)
+}
+
+// sendMessage 发送群聊或私聊信息
+func (bot *Bot) sendMessage(ctx context.Context, messageType string, targetId int64, message any, autoEscape bool) (*SentMessage, error) {
+	content, autoEscape, err := bot.messageContent(message, autoEscape)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
-	res := bot.getActionResult(req.Echo)
-	if res.Status != "ok" {
-		return &ActionFailErr{res.Wording}
+	params := map[string]interface{}{
+		"message":     content,
+		"auto_escape": autoEscape,
 	}
 
-	return nil
+	var action string
+	switch messageType {
+	case GroupMessageType:
+		action = "send_group_msg"
+		params["group_id"] = targetId
+	case PrivateMessageType:
+		action = "send_private_msg"
+		params["user_id"] = targetId
+	default:
+		return nil, &InvalidMessageErr{"unknown message type " + messageType}
+	}
+
+	res, err := bot.call(ctx, action, params)
+	if err != nil {
+		return nil, err
+	}
+
+	return bot.newSentMessage(messageType, targetId, res), nil
 }
 
-// SendGroupMessage 发送群聊信息
-// message string 消息文本
-// id int64 群组 ID
-func (bot *Bot) SendGroupMessage(message string, groupId int64, autoEscape bool) error {
-	req := CqRequest{
-		Action: "send_group_msg",
-		Params: map[string]interface{}{
-			"message":     message,
-			"group_id":    groupId,
-			"auto_escape": autoEscape,
-		},
-		Echo: uuid.NewV4().String(),
+// newSentMessage 由发送消息的响应构建 SentMessage
+func (bot *Bot) newSentMessage(messageType string, targetId int64, res *CqResponse) *SentMessage {
+	return &SentMessage{
+		MessageId:   res.Json.Get("data.message_id").Int(),
+		ForwardId:   res.Json.Get("data.forward_id").String(),
+		MessageType: messageType,
+		TargetId:    targetId,
+		Time:        time.Now().Unix(),
+
+		Bot: bot,
 	}
+}
 
-	err := bot.doAction(&req)
+// messageContent 按 MessageFormat 将消息内容转换为请求参数
+// 消息链总是以 auto_escape=false 发送, 转换后的 autoEscape 随之返回
+func (bot *Bot) messageContent(message any, autoEscape bool) (any, bool, error) {
+	if content, ok := message.(string); ok && bot.MessageFormat != ArrayMessageFormat && !bot.EmbedLocalFiles {
+		return content, autoEscape, nil
+	}
 
+	chain, err := toMessageChain(message, autoEscape)
 	if err != nil {
-		return err
+		return nil, false, err
 	}
 
-	res := bot.getActionResult(req.Echo)
-	if res.Status != "ok" {
-		return &ActionFailErr{res.Wording}
+	if bot.EmbedLocalFiles {
+		chain, err = embedLocalFiles(chain)
+		if err != nil {
+			return nil, false, err
+		}
 	}
 
-	return nil
+	if bot.MessageFormat == StringMessageFormat {
+		return chain.String(), false, nil
+	}
+	return chain, false, nil
+}
+
+// toMessageChain 将消息内容转换为消息链
+// autoEscape bool message 为 string 时是否作为纯文本
+func toMessageChain(message any, autoEscape bool) (MessageChain, error) {
+	switch content := message.(type) {
+	case string:
+		if autoEscape {
+			return NewMessage().Text(content).Build(), nil
+		}
+		return ParseCQString(content), nil
+	case MessageChain:
+		return content, nil
+	case []Segment:
+		return content, nil
+	case *MessageBuilder:
+		if content.Err() != nil {
+			return nil, content.Err()
+		}
+		return content.Build(), nil
+	case Segment:
+		return MessageChain{content}, nil
+	}
+
+	return nil, &InvalidMessageErr{fmt.Sprintf("unsupported message type %T", message)}
 }
 
 // GetMessage 获取消息
 // messageId int64 消息ID Not real_id
 func (bot *Bot) GetMessage(messageId int64, furtherInfo bool) (*Message, error) {
-	req := CqRequest{
-		Action: "get_msg",
-		Params: map[string]interface{}{
-			"message_id": messageId,
-		},
-		Echo: uuid.NewV4().String(),
-	}
+	return bot.GetMessageCtx(context.Background(), messageId, furtherInfo)
+}
 
-	err := bot.doAction(&req)
+// GetMessageCtx 获取消息
+func (bot *Bot) GetMessageCtx(ctx context.Context, messageId int64, furtherInfo bool) (*Message, error) {
+	res, err := bot.call(ctx, "get_msg", map[string]interface{}{
+		"message_id": messageId,
+	})
 	if err != nil {
-		return nil, &ActionFailErr{Message: err.Error()}
+		return nil, err
 	}
 
-	res := bot.getActionResult(req.Echo)
-	if res.Status != "ok" {
-		return nil, &ActionFailErr{res.Wording}
-	}
+	chain := ParseMessage(res.Json.Get("data.message"))
 
 	msg := &Message{
 		MessageType: res.Json.Get("data.message_type").String(),
 		MessageID:   res.Json.Get("data.message_id").Int(),
+		Time:        res.Json.Get("data.time").Int(),
 		Sender: &User{
 			UserId:   res.Json.Get("data.sender.user_id").Int(),
 			NickName: res.Json.Get("data.sender.nickname").String(),
 		},
-		RawMessage: res.Json.Get("data.message").String(),
+		RawMessage: chain.String(),
+		Chain:      chain,
 
 		Bot: bot,
 	}
@@ -268,7 +438,7 @@ func (bot *Bot) GetMessage(messageId int64, furtherInfo bool) (*Message, error)
 	if msg.IsGroupMessage() {
 		groupId := res.Json.Get("data.group_id").Int()
 
-		grpMember, err := bot.GetGroupMember(groupId, msg.Sender.UserId)
+		grpMember, err := bot.GetGroupMemberCtx(ctx, groupId, msg.Sender.UserId)
 		if err != nil {
 			return nil, err
 		}
@@ -280,39 +450,38 @@ func (bot *Bot) GetMessage(messageId int64, furtherInfo bool) (*Message, error)
 	return msg, nil
 }
 
-func (bot *Bot) GetGroupList() [][]any {
-	req := CqRequest{
-		Action: "get_group_list",
-		Echo:   uuid.NewV4().String(),
-	}
-
-	err := bot.doAction(&req)
-	if err != nil {
-		log.Error("获取群组列表失败:", err)
-		return nil
-	}
-
-	res := bot.getActionResult(req.Echo)
-	if res.Status != "ok" {
-		return nil
-	}
+// DeleteMessage 撤回消息
+func (bot *Bot) DeleteMessage(messageId int64) error {
+	return bot.DeleteMessageCtx(context.Background(), messageId)
+}
 
-	groupList := make([][]any, 0)
+// DeleteMessageCtx 撤回消息
+func (bot *Bot) DeleteMessageCtx(ctx context.Context, messageId int64) error {
+	_, err := bot.call(ctx, "delete_msg", map[string]interface{}{
+		"message_id": messageId,
+	})
+	return err
+}
 
-	groups := res.Json.Get("data").Array()
+// GetGroupList 获取群列表
+func (bot *Bot) GetGroupList() ([]GroupInfo, error) {
+	return bot.GetGroupListCtx(context.Background())
+}
 
-	for _, group := range groups {
-		groupId := group.Get("group_id").Int()
-		groupName := group.Get("group_name").String()
-		groupList = append(groupList, append(make([]any, 0), groupId, groupName))
-	}
-	return groupList
+// GetGroupListCtx 获取群列表
+func (bot *Bot) GetGroupListCtx(ctx context.Context) ([]GroupInfo, error) {
+	return CallAction[[]GroupInfo](ctx, bot, "get_group_list", nil)
 }
 
 func (bot *Bot) GetAvatar(userId int64, size int) (image.Image, error) {
+	return bot.GetAvatarCtx(context.Background(), userId, size)
+}
+
+// GetAvatarCtx 获取头像
+func (bot *Bot) GetAvatarCtx(ctx context.Context, userId int64, size int) (image.Image, error) {
 	url := fmt.Sprintf("https://q1.qlogo.cn/g?b=qq&nk=%d&s=%d", userId, size)
 
-	imageData, err := getHttpRes(url)
+	imageData, err := getHttpRes(ctx, url)
 	if err != nil {
 		log.Error("获取头像失败:", err)
 		return nil, err
@@ -329,39 +498,21 @@ func (bot *Bot) GetAvatar(userId int64, size int) (image.Image, error) {
 }
 
 func (bot *Bot) GetGroupMember(groupId int64, userId int64) (*GroupMember, error) {
-	req := CqRequest{
-		Action: "get_group_member_info",
-		Params: map[string]interface{}{
-			"group_id": groupId,
-			"user_id":  userId,
-		},
-		Echo: uuid.NewV4().String(),
-	}
+	return bot.GetGroupMemberCtx(context.Background(), groupId, userId)
+}
 
-	err := bot.doAction(&req)
+// GetGroupMemberCtx 获取群成员信息
+func (bot *Bot) GetGroupMemberCtx(ctx context.Context, groupId int64, userId int64) (*GroupMember, error) {
+	grpMember, err := CallAction[GroupMember](ctx, bot, "get_group_member_info", map[string]interface{}{
+		"group_id": groupId,
+		"user_id":  userId,
+	})
 	if err != nil {
-		return nil, &ActionFailErr{Message: "failed to get group member info " + err.Error()}
-	}
-
-	res := bot.getActionResult(req.Echo)
-	if res.Status != "ok" {
-		return nil, &ActionFailErr{Message: "failed to get group member info " + res.Wording}
+		return nil, err
 	}
 
-	grpMember := GroupMember{
-		User: &User{
-			UserId:   res.Json.Get("data.user_id").Int(),
-			NickName: res.Json.Get("data.nickname").String(),
-		},
-		Card: res.Json.Get("data.card").String(),
-
-		JoinTime:        res.Json.Get("data.join_time").Int(),
-		LastSentTime:    res.Json.Get("data.last_sent_time").Int(),
-		Level:           res.Json.Get("data.level").String(),
-		Role:            res.Json.Get("data.role").String(),
-		CardChangeable:  res.Json.Get("data.card_changeable").Bool(),
-		ShutUpTimeStamp: res.Json.Get("data.shut_up_timestamp").Int(),
-	}
+	grpMember.GroupId = groupId
+	grpMember.Bot = bot
 
 	return &grpMember, nil
 }
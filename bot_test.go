@@ -0,0 +1,318 @@
+package hareru_cq
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/tidwall/gjson"
+)
+
+// fakeCqHttp 通用端点的 cqhttp 模拟, 对每个请求回传相同 echo 的成功响应
+type fakeCqHttp struct {
+	*httptest.Server
+
+	lock      sync.Mutex
+	writeLock sync.Mutex
+	conns     []*websocket.Conn
+	received  chan gjson.Result // 收到的请求
+}
+
+func newFakeCqHttp(t *testing.T) *fakeCqHttp {
+	fake := &fakeCqHttp{received: make(chan gjson.Result, 16)}
+	fake.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, err := websocket.Upgrade(w, r, nil, 0, 0)
+		if err != nil {
+			return
+		}
+
+		fake.lock.Lock()
+		fake.conns = append(fake.conns, conn)
+		fake.lock.Unlock()
+
+		go fake.serve(conn)
+	}))
+	t.Cleanup(fake.Close)
+	return fake
+}
+
+func (fake *fakeCqHttp) serve(conn *websocket.Conn) {
+	for {
+		_, frame, err := conn.ReadMessage()
+		if err != nil {
+			return
+		}
+
+		request := gjson.ParseBytes(frame)
+		action := request.Get("action").String()
+		select {
+		case fake.received <- request:
+		default:
+		}
+
+		// 以目标 ID 作为 message_id, 便于校验响应与请求的对应关系
+		data := map[string]any{"message_id": request.Get("params.group_id").Int()}
+		switch action {
+		case "get_login_info":
+			data = map[string]any{"user_id": 10, "nickname": "bot"}
+		case "never_respond":
+			continue
+		}
+
+		// 并发回传, 响应顺序与请求顺序无关
+		go func() {
+			fake.writeLock.Lock()
+			defer fake.writeLock.Unlock()
+			_ = conn.WriteJSON(map[string]any{
+				"status":  "ok",
+				"retcode": 0,
+				"data":    data,
+				"echo":    request.Get("echo").String(),
+			})
+		}()
+	}
+}
+
+// push 向全部连接上报事件
+func (fake *fakeCqHttp) push(event map[string]any) {
+	fake.lock.Lock()
+	defer fake.lock.Unlock()
+	fake.writeLock.Lock()
+	defer fake.writeLock.Unlock()
+	for _, conn := range fake.conns {
+		_ = conn.WriteJSON(event)
+	}
+}
+
+// dropAll 断开全部连接
+func (fake *fakeCqHttp) dropAll() {
+	fake.lock.Lock()
+	defer fake.lock.Unlock()
+	for _, conn := range fake.conns {
+		_ = conn.Close()
+	}
+	fake.conns = nil
+}
+
+// waitRequest 等待指定 Action 的请求, 跳过其他请求
+func (fake *fakeCqHttp) waitRequest(t *testing.T, action string) gjson.Result {
+	t.Helper()
+
+	timeout := time.After(5 * time.Second)
+	for {
+		select {
+		case request := <-fake.received:
+			if request.Get("action").String() == action {
+				return request
+			}
+		case <-timeout:
+			t.Fatalf("%s not received", action)
+			return gjson.Result{}
+		}
+	}
+}
+
+func (fake *fakeCqHttp) wsUrl() string {
+	return "ws" + strings.TrimPrefix(fake.URL, "http")
+}
+
+// newTestBot 连接 fake 并初始化 Bot
+func newTestBot(t *testing.T, fake *fakeCqHttp) *Bot {
+	client := NewClient(fake.wsUrl(), "")
+	client.Universal = true
+	bot := &Bot{Client: client}
+
+	err := bot.Init()
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(bot.Stop)
+	return bot
+}
+
+func TestBotReinitRegistersHooksOnce(t *testing.T) {
+	fake := newFakeCqHttp(t)
+	bot := newTestBot(t, fake)
+	updater := &Updater{Updates: make(chan *Update, 10), Bot: bot}
+	err := updater.Init()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	bot.Stop()
+	err = bot.Init()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(bot.Client.onConnect) != 1 || len(bot.Client.onDisconnect) != 1 || len(bot.Client.onAuthFailed) != 1 {
+		t.Errorf("hooks registered %d/%d/%d times, want 1/1/1",
+			len(bot.Client.onConnect), len(bot.Client.onDisconnect), len(bot.Client.onAuthFailed))
+	}
+	if info := bot.Info(); info == nil || info.UserId != 10 {
+		t.Errorf("Info() = %+v, want user 10", info)
+	}
+
+	// 重新初始化后事件仍送达原 Updater
+	fake.push(map[string]any{"post_type": "notice", "notice_type": "test"})
+	timeout := time.After(5 * time.Second)
+	for {
+		select {
+		case update := <-updater.Updates:
+			if update.Event.Get("notice_type").String() == "test" {
+				return
+			}
+		case <-timeout:
+			t.Fatal("event not delivered after re-init")
+		}
+	}
+}
+
+func TestParallelSendGroupMessage(t *testing.T) {
+	fake := newFakeCqHttp(t)
+	bot := newTestBot(t, fake)
+
+	const senders = 200
+	var wg sync.WaitGroup
+	errs := make(chan error, senders)
+	for i := 1; i <= senders; i++ {
+		wg.Add(1)
+		go func(groupId int64) {
+			defer wg.Done()
+
+			sent, err := bot.SendGroupMessage("hello", groupId, false)
+			if err != nil {
+				errs <- err
+				return
+			}
+			if sent.MessageId != groupId {
+				errs <- fmt.Errorf("group %d got response for group %d", groupId, sent.MessageId)
+			}
+		}(int64(i))
+	}
+	wg.Wait()
+	close(errs)
+
+	for err := range errs {
+		t.Error(err)
+	}
+}
+
+func TestPendingActionFailsOnDisconnect(t *testing.T) {
+	fake := newFakeCqHttp(t)
+	bot := newTestBot(t, fake)
+
+	result := make(chan error, 1)
+	go func() {
+		_, err := bot.call(context.Background(), "never_respond", nil)
+		result <- err
+	}()
+
+	// 等待请求到达后断开连接
+	for request := range fake.received {
+		if request.Get("action").String() == "never_respond" {
+			break
+		}
+	}
+	fake.dropAll()
+
+	select {
+	case err := <-result:
+		var lostErr *ConnectionLostErr
+		if !errors.As(err, &lostErr) {
+			t.Errorf("err = %v, want ConnectionLostErr", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("pending action not failed after disconnect")
+	}
+}
+
+func TestEventsDoNotBlockResponses(t *testing.T) {
+	fake := newFakeCqHttp(t)
+	bot := newTestBot(t, fake)
+	bot.ActionTimeout = 2 * time.Second
+
+	// 未启动 Updater, 事件超出队列容量
+	for i := 0; i < 2*cap(bot.Client.events); i++ {
+		fake.push(map[string]any{"post_type": "meta_event", "meta_event_type": "heartbeat"})
+	}
+
+	sent, err := bot.SendGroupMessage("hello", 1, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if sent.MessageId != 1 {
+		t.Errorf("MessageId = %d, want 1", sent.MessageId)
+	}
+}
+
+// pendingCount 等待响应的请求数
+func pendingCount(bot *Bot) int {
+	bot.pending.lock.Lock()
+	defer bot.pending.lock.Unlock()
+	return len(bot.pending.pending)
+}
+
+func TestActionTimeout(t *testing.T) {
+	fake := newFakeCqHttp(t)
+	bot := newTestBot(t, fake)
+	bot.ActionTimeout = 50 * time.Millisecond
+
+	_, err := bot.call(context.Background(), "never_respond", nil)
+	var timeoutErr *ActionTimeoutErr
+	if !errors.As(err, &timeoutErr) || timeoutErr.Action != "never_respond" {
+		t.Errorf("err = %v, want ActionTimeoutErr", err)
+	}
+
+	// ctx 的截止时间优先于 ActionTimeout
+	bot.ActionTimeout = time.Minute
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	_, err = bot.call(ctx, "never_respond", nil)
+	if !errors.As(err, &timeoutErr) {
+		t.Errorf("err = %v, want ActionTimeoutErr", err)
+	}
+
+	if n := pendingCount(bot); n != 0 {
+		t.Errorf("%d pending responses left after timeout", n)
+	}
+}
+
+func TestActionCancel(t *testing.T) {
+	fake := newFakeCqHttp(t)
+	bot := newTestBot(t, fake)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	result := make(chan error, 1)
+	go func() {
+		_, err := bot.call(ctx, "never_respond", nil)
+		result <- err
+	}()
+
+	for request := range fake.received {
+		if request.Get("action").String() == "never_respond" {
+			break
+		}
+	}
+	cancel()
+
+	select {
+	case err := <-result:
+		if !errors.Is(err, context.Canceled) {
+			t.Errorf("err = %v, want context.Canceled", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("action not cancelled")
+	}
+
+	if n := pendingCount(bot); n != 0 {
+		t.Errorf("%d pending responses left after cancel", n)
+	}
+}
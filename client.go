@@ -1,21 +1,67 @@
 package hareru_cq
 
 import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
 	log "github.com/sirupsen/logrus"
 
 	"github.com/gorilla/websocket"
 	"github.com/tidwall/gjson"
 )
 
+// ClientMode 连接方式
+type ClientMode int
+
+const (
+	ForwardWsMode ClientMode = iota // 正向 WebSocket, 由 Client 连接 cqhttp
+	ReverseWsMode                   // 反向 WebSocket, 由 cqhttp 连接 Client
+	HttpMode                        // HTTP API 调用 + HTTP POST 上报
+)
+
 // Client cqhttp客户端
 type Client struct {
 	WsUrl             string
 	AccessToken       string
 	EnableAccessToken bool
 
+	Mode       ClientMode
+	Universal  bool   // 正向 WebSocket 是否使用通用端点 (/), API 与事件共用一个连接
+	HttpUrl    string // HTTP API 地址
+	ListenAddr string // 反向 WebSocket / HTTP POST 监听地址
+	Secret     string // HTTP POST 上报签名密钥, 为空时不校验 X-Signature
+	SelfId     int64  // 反向 WebSocket 连接时 cqhttp 上报的 X-Self-ID
+
+	ReconnectInterval    time.Duration // 首次重连间隔, 之后指数增长
+	MaxReconnectInterval time.Duration // 最大重连间隔
+	PingInterval         time.Duration // WebSocket ping 间隔, 超过两个间隔无数据视为断开, 为 0 时不检测
+
 	ActConn     *websocket.Conn
 	EventConn   *websocket.Conn
 	initialized bool
+	connected   bool
+	closed      bool
+	authErr     error       // 重连时 AccessToken 被拒绝, 此后 Action 返回该错误
+	dropping    atomic.Bool // 事件队列已满, 正在丢弃事件
+
+	onConnect    []func()      // 重连成功
+	onDisconnect []func(error) // 连接断开
+	onAuthFailed []func(error) // 重连时 AccessToken 被拒绝
+
+	events    chan *eventFrame // 事件帧
+	responses chan []byte      // API 响应帧
+
+	httpClient *http.Client
+	server     *http.Server
+	ready      chan struct{} // 反向 WebSocket 连接就绪
+	connLock   sync.Mutex
+	writeLock  sync.Mutex
 }
 
 // CqRequest cqhttp请求
@@ -36,6 +82,12 @@ type CqResponse struct {
 	Json gjson.Result
 }
 
+// eventFrame 待处理的事件数据
+type eventFrame struct {
+	data  []byte
+	reply chan *QuickOperation // HTTP POST 上报时用于回传快速操作
+}
+
 // Event 事件
 type Event struct {
 	Time    int64  `json:"time"`
@@ -43,6 +95,9 @@ type Event struct {
 	SubType string `json:"sub_type"`
 
 	Json gjson.Result
+
+	chain     MessageChain
+	chainOnce sync.Once
 }
 
 // Get Json取 Event 数据
@@ -50,6 +105,23 @@ func (event *Event) Get(path string) gjson.Result {
 	return event.Json.Get(path)
 }
 
+// Message 消息事件的消息链, 兼容 post-message-format 为 string 与 array 的上报
+func (event *Event) Message() MessageChain {
+	event.chainOnce.Do(func() {
+		event.chain = ParseMessage(event.Get("message"))
+	})
+	return event.chain
+}
+
+// MessageString 消息事件的 CQ 码字符串, 数组格式的上报将被转换为 CQ 码
+func (event *Event) MessageString() string {
+	message := event.Get("message")
+	if message.Type == gjson.String {
+		return message.String()
+	}
+	return event.Message().String()
+}
+
 // NewClient 创建 Client
 func NewClient(wsUrl string, accessToken string) *Client {
 	var enableToken bool
@@ -61,35 +133,236 @@ func NewClient(wsUrl string, accessToken string) *Client {
 		WsUrl:             wsUrl,
 		AccessToken:       accessToken,
 		EnableAccessToken: enableToken,
+		Mode:              ForwardWsMode,
+
+		ReconnectInterval:    time.Second,
+		MaxReconnectInterval: time.Minute,
+		PingInterval:         30 * time.Second,
+
+		events:    make(chan *eventFrame, 100),
+		responses: make(chan []byte, 100),
 	}
 }
 
+// NewReverseClient 创建反向 WebSocket Client
+// listenAddr string 监听地址, 如 0.0.0.0:8080
+func NewReverseClient(listenAddr string, accessToken string) *Client {
+	client := NewClient("", accessToken)
+	client.Mode = ReverseWsMode
+	client.ListenAddr = listenAddr
+
+	return client
+}
+
+// NewHttpClient 创建 HTTP Client
+// httpUrl string HTTP API 地址
+// listenAddr string HTTP POST 上报监听地址
+func NewHttpClient(httpUrl string, listenAddr string, accessToken string) *Client {
+	client := NewClient("", accessToken)
+	client.Mode = HttpMode
+	client.HttpUrl = httpUrl
+	client.ListenAddr = listenAddr
+	client.httpClient = &http.Client{}
+
+	return client
+}
+
 // connect 连接 websocket API
 func (c *Client) connect() error {
-	actConn, _, err := websocket.DefaultDialer.Dial(c.WsUrl+"/api", nil)
+	switch c.Mode {
+	case ReverseWsMode:
+		return c.listen()
+	case HttpMode:
+		if c.Secret == "" && !c.EnableAccessToken {
+			log.Warnln("未设置 Secret 与 AccessToken, HTTP POST 上报将接受任意来源的事件")
+		}
+
+		err := c.startServer(http.HandlerFunc(c.servePost))
+		if err != nil {
+			return err
+		}
+
+		log.Infoln("HTTP POST 上报服务已启动:", c.ListenAddr)
+		return nil
+	}
+
+	actConn, eventConn, err := c.dialConns()
 	if err != nil {
-		log.Fatal("WebSocket连接失败:", err)
 		return err
 	}
 
-	eventConn, _, err := websocket.DefaultDialer.Dial(c.WsUrl+"/event", nil)
+	c.setConns(actConn, eventConn)
+
+	log.Infoln("cqHttp 连接成功")
+
+	return nil
+}
+
+// dialConns 连接 API 与事件 WebSocket, 通用端点时两者为同一连接
+func (c *Client) dialConns() (*websocket.Conn, *websocket.Conn, error) {
+	if c.Universal {
+		conn, err := c.dial(strings.TrimSuffix(c.WsUrl, "/") + "/")
+		if err != nil {
+			return nil, nil, err
+		}
+
+		return conn, conn, nil
+	}
+
+	actConn, err := c.dial(c.WsUrl + "/api")
 	if err != nil {
-		log.Fatal("WebSocket连接失败:", err)
-		return err
+		return nil, nil, err
 	}
 
+	eventConn, err := c.dial(c.WsUrl + "/event")
+	if err != nil {
+		_ = actConn.Close()
+		return nil, nil, err
+	}
+
+	return actConn, eventConn, nil
+}
+
+// setConns 启用新连接
+func (c *Client) setConns(actConn *websocket.Conn, eventConn *websocket.Conn) {
+	c.connLock.Lock()
+	defer c.connLock.Unlock()
+
 	c.ActConn = actConn
 	c.EventConn = eventConn
+	c.connected = true
+	c.authErr = nil
 
-	log.Infoln("cqHttp 连接成功")
+	go c.readLoop(actConn)
+	if eventConn != actConn {
+		go c.readLoop(eventConn)
+	}
+}
+
+// dial 连接 WebSocket, AccessToken 被拒绝时返回 AuthFailedErr
+func (c *Client) dial(url string) (*websocket.Conn, error) {
+	conn, response, err := websocket.DefaultDialer.Dial(url, c.authHeader())
+	if err != nil {
+		authErr := checkAuthResponse(response)
+		if authErr != nil {
+			err = authErr
+		}
+
+		log.Error("WebSocket连接失败:", err)
+		return nil, err
+	}
+
+	return conn, nil
+}
+
+// readLoop 读取连接上的数据帧并分发
+func (c *Client) readLoop(conn *websocket.Conn) {
+	conn.SetPongHandler(func(string) error {
+		c.extendDeadline(conn)
+		return nil
+	})
+	go c.keepAlive(conn)
+
+	for {
+		c.extendDeadline(conn)
+
+		_, frame, err := conn.ReadMessage()
+		if err != nil {
+			c.connectionLost(conn, err)
+			return
+		}
+
+		c.dispatch(frame)
+	}
+}
+
+// dispatch 区分事件 (含 post_type) 与 API 响应 (含 echo)
+func (c *Client) dispatch(frame []byte) {
+	result := gjson.GetManyBytes(frame, "post_type", "echo")
+
+	switch {
+	case result[0].Exists():
+		c.queueEvent(&eventFrame{data: frame})
+	case result[1].Exists():
+		c.responses <- frame
+	default:
+		log.Debugln("忽略无法识别的数据:", string(frame))
+	}
+}
+
+// queueEvent 将事件放入队列, 队列已满 (如未启动 Updater) 时丢弃
+// 事件与 API 响应由同一读取协程分发, 阻塞等待会使 Action 全部超时
+func (c *Client) queueEvent(frame *eventFrame) {
+	select {
+	case c.events <- frame:
+		c.dropping.Store(false)
+	default:
+		if !c.dropping.Swap(true) {
+			log.Warnln("事件队列已满, 丢弃事件直至队列可用 (是否未启动 Updater?)")
+		}
+		log.Debugln("丢弃事件:", string(frame.data))
+	}
+}
+
+// writeRequest 通过 API 连接发送请求, 写入互斥进行 (WebSocket 不支持并发写)
+func (c *Client) writeRequest(ctx context.Context, req *CqRequest) error {
+	c.connLock.Lock()
+	conn, authErr := c.ActConn, c.authErr
+	c.connLock.Unlock()
+
+	if conn == nil {
+		if authErr != nil {
+			return authErr
+		}
+		return &ConnectionLostErr{"cqHttp 未连接"}
+	}
+
+	c.writeLock.Lock()
+	defer c.writeLock.Unlock()
+
+	deadline, _ := ctx.Deadline()
+	_ = conn.SetWriteDeadline(deadline)
+
+	err := conn.WriteJSON(req)
+	if err != nil {
+		// 写入失败 (含超时) 后连接不可再用
+		go c.connectionLost(conn, err)
+	}
+
+	return err
+}
+
+// startServer 在 ListenAddr 上启动 HTTP 服务
+func (c *Client) startServer(handler http.Handler) error {
+	listener, err := net.Listen("tcp", c.ListenAddr)
+	if err != nil {
+		log.Error("监听失败:", err)
+		return err
+	}
+
+	c.server = &http.Server{Handler: handler}
+
+	go func() {
+		err := c.server.Serve(listener)
+		if err != nil && !errors.Is(err, http.ErrServerClosed) {
+			log.Error("HTTP 服务出错:", err)
+		}
+	}()
 
 	return nil
 }
 
 // Close 关闭连接
 func (c *Client) Close() {
-	_ = c.ActConn.Close()
-	_ = c.EventConn.Close()
+	c.connLock.Lock()
+	c.closed = true
+	c.connected = false
+	c.closeConns()
+	c.connLock.Unlock()
+
+	if c.server != nil {
+		_ = c.server.Close()
+	}
 	c.initialized = false
 }
 
@@ -102,9 +375,20 @@ func (c *Client) Init() error {
 		}
 	}
 
+	// 事件与响应通道在多次 Init 间复用, Updater 与 ResponseUpdater 只需启动一次
+	if c.events == nil {
+		c.events = make(chan *eventFrame, 100)
+		c.responses = make(chan []byte, 100)
+	}
+	c.ready = make(chan struct{})
+
+	c.connLock.Lock()
+	c.closed = false
+	c.connLock.Unlock()
+
 	err := c.connect()
 	if err != nil {
-		log.Fatal("Client 初始化失败:", err)
+		log.Error("Client 初始化失败:", err)
 		return err
 	}
 
@@ -0,0 +1,316 @@
+package hareru_cq
+
+import (
+	"reflect"
+	"strings"
+	"unicode"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// DefaultCommandPrefixes 默认命令前缀
+var DefaultCommandPrefixes = []string{"/", "!", "#"}
+
+// CommandContext 命令上下文
+type CommandContext struct {
+	Update  *Update
+	Message *Message
+	Bot     *Bot
+	Handler *CommandHandler // 匹配到的命令
+
+	Prefix  string   // 触发时使用的前缀, 通过 @Bot 省略前缀时为空
+	Name    string   // 触发时使用的命令名或别名
+	Args    []string // 参数, 引号内的空白不作分隔, @某人 作为其 QQ 号的单独参数
+	RawArgs string   // 命令名之后的原始文本, @某人 以 QQ 号表示, 其他非文本消息段被忽略
+}
+
+// Arg 取第 i 个参数, 不存在时返回空字符串
+func (c *CommandContext) Arg(i int) string {
+	if i < 0 || i >= len(c.Args) {
+		return ""
+	}
+	return c.Args[i]
+}
+
+// Reply 引用触发命令的消息回复
+func (c *CommandContext) Reply(message any) (*SentMessage, error) {
+	return c.Message.Reply(message, ReplyOptions{Quote: true})
+}
+
+// CommandHandler 消息命令处理器
+//
+// 命令名须与消息中前缀后的第一个词完全一致, 如 Command 为 foo 时不匹配 /foobar
+type CommandHandler struct {
+	Command       string                    //命令名
+	Aliases       []string                  //命令别名
+	Prefixes      []string                  //命令前缀, 为 nil 时使用 DefaultCommandPrefixes, 由 CommandRouter 管理时忽略
+	MentionPrefix bool                      //@Bot 时可省略前缀, 由 CommandRouter 管理时忽略
+	Filter        Filter                    //命令过滤器, 如权限限制, 为 nil 时不限制
+	Callback      func(*CommandContext) any //命令处理函数
+
+	Description string   //命令说明
+	Usage       string   //参数用法, 如 "<qq> [时长]", NewTypedCommandHandler 会由参数结构体生成
+	Examples    []string //示例, 不含前缀, 如 "ban 123456 10m"
+	Category    string   //分类, 帮助列表按分类分组
+	Hidden      bool     //不在帮助列表中显示, 仍可通过 help <命令> 查看
+}
+
+// Alias 添加命令别名
+func (h *CommandHandler) Alias(aliases ...string) *CommandHandler {
+	h.Aliases = append(h.Aliases, aliases...)
+	return h
+}
+
+// Match 命令名或别名是否为 name
+func (h *CommandHandler) Match(name string) bool {
+	if h.Command == name {
+		return true
+	}
+	for _, alias := range h.Aliases {
+		if alias == name {
+			return true
+		}
+	}
+	return false
+}
+
+// Permitted 触发 update 的用户是否可执行该命令
+func (h *CommandHandler) Permitted(update *Update) bool {
+	return h.Filter == nil || h.Filter.Filter(update)
+}
+
+func (h *CommandHandler) prefixes() []string {
+	if h.Prefixes == nil {
+		return DefaultCommandPrefixes
+	}
+	return h.Prefixes
+}
+
+func (h *CommandHandler) CheckUpdate(update *Update) bool {
+	command, ok := parseCommand(update, h.prefixes(), h.MentionPrefix)
+	return ok && h.Match(command.Name) && h.Permitted(update)
+}
+
+func (h *CommandHandler) HandleUpdate(update *Update) interface{} {
+	command, _ := parseCommand(update, h.prefixes(), h.MentionPrefix)
+	return h.run(update, command)
+}
+
+// CollectArgs 参数在 HandleUpdate 中解析至 CommandContext, 此处无需处理
+func (h *CommandHandler) CollectArgs(update *Update) {
+	return
+}
+
+// run 构建 CommandContext 并执行命令
+func (h *CommandHandler) run(update *Update, command *CommandContext) interface{} {
+	command.Update = update
+	command.Message = buildMessageByUpdate(update)
+	command.Bot = update.Bot
+	command.Handler = h
+	return h.Callback(command)
+}
+
+func NewCommandHandler(command string, callback func(*CommandContext) any) CommandHandler {
+	return CommandHandler{
+		Command:  command,
+		Callback: callback,
+	}
+}
+
+// NewTypedCommandHandler 构建带类型参数的命令处理器
+// 参数按 CommandContext.Bind 绑定到 *T, 绑定或校验失败时回复错误信息且不调用 callback
+func NewTypedCommandHandler[T any](command string, callback func(*CommandContext, *T) any) CommandHandler {
+	handler := NewCommandHandler(command, func(c *CommandContext) any {
+		args := new(T)
+		err := c.Bind(args)
+		if err != nil {
+			// 错误信息可能包含用户输入, 以纯文本回复
+			text := err.Error() + "\n用法: " + c.Handler.UsageLine(c.Prefix)
+			_, err = c.Message.Reply(text, ReplyOptions{Quote: true, AutoEscape: true})
+			if err != nil {
+				log.Error("回复参数错误失败:", err)
+			}
+			return nil
+		}
+
+		return callback(c, args)
+	})
+
+	argsType := reflect.TypeOf((*T)(nil)).Elem()
+	if argsType.Kind() == reflect.Struct {
+		if _, err := argFields(argsType); err != nil {
+			log.Error("命令 ", command, " 的参数定义有误: ", err)
+		}
+	}
+	handler.Usage = argsUsage(argsType)
+	return handler
+}
+
+// CommandRouter 命令路由, 以统一的前缀管理多个命令, 每条消息至多执行一个命令
+//
+//	router := NewCommandRouter("/", "#")
+//	router.Command("ping", func(c *CommandContext) any { return QuickReply("pong") }).Alias("p")
+//	app.AddHandler(router)
+type CommandRouter struct {
+	Prefixes      []string //命令前缀
+	MentionPrefix bool     //@Bot 时可省略前缀
+	Filter        Filter   //路由过滤器, 作用于所有命令, 为 nil 时不限制
+
+	Help         *CommandHandler //自动注册的帮助命令, 优先级低于同名命令, 为 nil 时禁用
+	HelpPageSize int             //帮助列表每页命令数
+	HelpForward  bool            //帮助列表多于一页时以合并转发发送全部命令, 而非分页
+
+	commands []*CommandHandler
+}
+
+// Add 注册命令, 应在 Application 运行前完成
+func (r *CommandRouter) Add(commands ...*CommandHandler) *CommandRouter {
+	r.commands = append(r.commands, commands...)
+	return r
+}
+
+// Command 注册命令并返回其 CommandHandler 以便继续设置
+func (r *CommandRouter) Command(command string, callback func(*CommandContext) any) *CommandHandler {
+	handler := NewCommandHandler(command, callback)
+	r.Add(&handler)
+	return &handler
+}
+
+// Commands 已注册的命令, 包括帮助命令
+func (r *CommandRouter) Commands() []*CommandHandler {
+	if r.Help == nil {
+		return r.commands
+	}
+	return append(r.commands[:len(r.commands):len(r.commands)], r.Help)
+}
+
+// Find 按命令名或别名查找命令, 不存在时返回 nil
+func (r *CommandRouter) Find(name string) *CommandHandler {
+	for _, command := range r.Commands() {
+		if command.Match(name) {
+			return command
+		}
+	}
+	return nil
+}
+
+// route 解析 update 并查找可执行的命令
+func (r *CommandRouter) route(update *Update) (*CommandHandler, *CommandContext) {
+	command, ok := parseCommand(update, r.Prefixes, r.MentionPrefix)
+	if !ok {
+		return nil, nil
+	}
+	if r.Filter != nil && !r.Filter.Filter(update) {
+		return nil, nil
+	}
+
+	for _, handler := range r.Commands() {
+		if handler.Match(command.Name) && handler.Permitted(update) {
+			return handler, command
+		}
+	}
+	return nil, nil
+}
+
+func (r *CommandRouter) CheckUpdate(update *Update) bool {
+	handler, _ := r.route(update)
+	return handler != nil
+}
+
+func (r *CommandRouter) HandleUpdate(update *Update) interface{} {
+	handler, command := r.route(update)
+	if handler == nil {
+		return nil
+	}
+	return handler.run(update, command)
+}
+
+func (r *CommandRouter) CollectArgs(update *Update) {
+	return
+}
+
+// NewCommandRouter 构建命令路由
+// prefixes ...string 命令前缀, 为空时使用 DefaultCommandPrefixes
+func NewCommandRouter(prefixes ...string) *CommandRouter {
+	if len(prefixes) == 0 {
+		prefixes = DefaultCommandPrefixes
+	}
+	router := &CommandRouter{
+		Prefixes:     prefixes,
+		HelpPageSize: DefaultHelpPageSize,
+	}
+	router.Help = router.newHelpCommand()
+	return router
+}
+
+// parseCommand 解析消息中的命令, 忽略开头的回复与 @Bot
+// 仅在 @Bot 且 mentionPrefix 为 true 时允许省略前缀
+func parseCommand(update *Update, prefixes []string, mentionPrefix bool) (*CommandContext, bool) {
+	if update.Event.Type != "message" {
+		return nil, false
+	}
+
+	chain := update.Event.Message()
+	selfId := update.Event.Get("self_id").Int()
+	mentioned := false
+
+	start := 0
+	for ; start < len(chain); start++ {
+		seg := chain[start]
+		if seg.Type == ReplySegment {
+			continue
+		}
+		if seg.Type == AtSegment && !mentioned && seg.Int("qq") == selfId {
+			mentioned = true
+			continue
+		}
+		if seg.Type == TextSegment && strings.TrimSpace(seg.Get("text")) == "" {
+			continue
+		}
+		break
+	}
+	if start == len(chain) || chain[start].Type != TextSegment {
+		return nil, false
+	}
+
+	text := strings.TrimSpace(commandText(chain[start:]))
+
+	command := &CommandContext{}
+	found := false
+	for _, prefix := range prefixes {
+		if strings.HasPrefix(text, prefix) {
+			command.Prefix = prefix
+			text = text[len(prefix):]
+			found = true
+			break
+		}
+	}
+	if !found && !(mentioned && mentionPrefix) {
+		return nil, false
+	}
+
+	command.Name, command.RawArgs = text, ""
+	if i := strings.IndexFunc(text, unicode.IsSpace); i >= 0 {
+		command.Name, command.RawArgs = text[:i], strings.TrimSpace(text[i:])
+	}
+	if command.Name == "" {
+		return nil, false
+	}
+
+	command.Args = splitArgs(command.RawArgs)
+	return command, true
+}
+
+// commandText 命令文本, @某人 以两侧带空白的 QQ 号表示, 使其成为单独的参数
+func commandText(chain MessageChain) string {
+	var builder strings.Builder
+	for _, seg := range chain {
+		switch seg.Type {
+		case TextSegment:
+			builder.WriteString(seg.Get("text"))
+		case AtSegment:
+			builder.WriteString(" " + seg.Get("qq") + " ")
+		}
+	}
+	return builder.String()
+}
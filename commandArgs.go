@@ -0,0 +1,288 @@
+package hareru_cq
+
+import (
+	"encoding"
+	"errors"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// closingQuotes 支持的引号及其闭合引号
+var closingQuotes = map[rune]rune{
+	'"':  '"',
+	'\'': '\'',
+	'“':  '”',
+	'‘':  '’',
+}
+
+// splitArgs 按空白分割参数, 引号内的空白不作分隔, 反斜杠转义引号与反斜杠
+// 引号未闭合时剩余文本作为一个参数
+func splitArgs(text string) []string {
+	args := make([]string, 0)
+
+	var arg strings.Builder
+	inArg := false
+	var closing rune
+	escaped := false
+
+	for _, r := range text {
+		switch {
+		case escaped:
+			arg.WriteRune(r)
+			escaped = false
+		case r == '\\':
+			escaped = true
+			inArg = true
+		case closing != 0:
+			if r == closing {
+				closing = 0
+			} else {
+				arg.WriteRune(r)
+			}
+		case closingQuotes[r] != 0:
+			closing = closingQuotes[r]
+			inArg = true
+		case unicode.IsSpace(r):
+			if inArg {
+				args = append(args, arg.String())
+				arg.Reset()
+				inArg = false
+			}
+		default:
+			arg.WriteRune(r)
+			inArg = true
+		}
+	}
+
+	if escaped {
+		arg.WriteRune('\\')
+	}
+	if inArg {
+		args = append(args, arg.String())
+	}
+
+	return args
+}
+
+// argField 绑定参数的结构体字段
+type argField struct {
+	name     string
+	optional bool
+	rest     bool
+	index    int
+}
+
+// argFields 解析结构体参数字段, 跳过未导出字段与 arg:"-"
+// 剩余参数 (含切片字段) 不是最后一个字段时返回 ArgumentErr
+func argFields(typ reflect.Type) ([]argField, error) {
+	fields := make([]argField, 0)
+	for i := 0; i < typ.NumField(); i++ {
+		field := typ.Field(i)
+		tag := field.Tag.Get("arg")
+		if !field.IsExported() || tag == "-" {
+			continue
+		}
+
+		options := strings.Split(tag, ",")
+		arg := argField{
+			name:  options[0],
+			index: i,
+		}
+		if arg.name == "" {
+			arg.name = strings.ToLower(field.Name)
+		}
+		for _, option := range options[1:] {
+			switch option {
+			case "optional":
+				arg.optional = true
+			case "rest":
+				arg.rest = true
+			}
+		}
+		if field.Type.Kind() == reflect.Slice && !reflect.PointerTo(field.Type).Implements(textUnmarshalerType) {
+			arg.rest = true
+			arg.optional = true
+		}
+
+		fields = append(fields, arg)
+	}
+
+	for i, field := range fields {
+		if field.rest && i != len(fields)-1 {
+			return nil, &ArgumentErr{Argument: field.name, Message: "剩余参数与切片字段须为最后一个字段"}
+		}
+	}
+	return fields, nil
+}
+
+// Bind 将参数按字段顺序绑定到结构体指针 v, 并在 v 实现 Validate() error 时进行校验
+//
+// 字段标签 arg:"name,optional,rest":
+//   - name 参数名, 用于错误信息, 默认为小写字段名
+//   - optional 可选参数, 缺失时保持零值
+//   - rest 剩余参数, 须为最后一个字段, string 字段以空格拼接剩余参数, 切片字段自动视为 rest
+//
+// 支持 string, bool, 整数, 浮点数, time.Duration, encoding.TextUnmarshaler 及其切片
+// 消息中的 @某人 作为其 QQ 号参数, 可绑定到整数字段, 如 /ban @某人 10m
+//
+//	type BanArgs struct {
+//		UserId   int64         `arg:"qq"`
+//		Duration time.Duration `arg:"时长,optional"`
+//	}
+func (c *CommandContext) Bind(v any) error {
+	value := reflect.ValueOf(v)
+	if value.Kind() != reflect.Pointer || value.Elem().Kind() != reflect.Struct {
+		return &ArgumentErr{Message: "绑定目标应为结构体指针"}
+	}
+	value = value.Elem()
+
+	fields, err := argFields(value.Type())
+	if err != nil {
+		return err
+	}
+
+	args := c.Args
+	for _, field := range fields {
+		target := value.Field(field.index)
+
+		if field.rest {
+			if len(args) == 0 && !field.optional {
+				return &ArgumentErr{Argument: field.name, Message: "缺失"}
+			}
+			err = setRest(target, args)
+			if err != nil {
+				return &ArgumentErr{Argument: field.name, Message: err.Error()}
+			}
+			args = nil
+			break
+		}
+
+		if len(args) == 0 {
+			if field.optional {
+				continue
+			}
+			return &ArgumentErr{Argument: field.name, Message: "缺失"}
+		}
+
+		err = setArg(target, args[0])
+		if err != nil {
+			return &ArgumentErr{Argument: field.name, Message: err.Error()}
+		}
+		args = args[1:]
+	}
+
+	if len(args) > 0 {
+		return &ArgumentErr{Message: "参数过多: " + strings.Join(args, " ")}
+	}
+
+	if validator, ok := v.(interface{ Validate() error }); ok {
+		return validator.Validate()
+	}
+	return nil
+}
+
+// setRest 绑定剩余参数
+func setRest(target reflect.Value, args []string) error {
+	if len(args) == 0 {
+		return nil
+	}
+	if target.Kind() != reflect.Slice || target.Addr().Type().Implements(textUnmarshalerType) {
+		return setArg(target, strings.Join(args, " "))
+	}
+
+	slice := reflect.MakeSlice(target.Type(), len(args), len(args))
+	for i, arg := range args {
+		err := setArg(slice.Index(i), arg)
+		if err != nil {
+			return err
+		}
+	}
+	target.Set(slice)
+	return nil
+}
+
+var (
+	durationType        = reflect.TypeOf(time.Duration(0))
+	textUnmarshalerType = reflect.TypeOf((*encoding.TextUnmarshaler)(nil)).Elem()
+)
+
+// setArg 将单个参数解析并赋值给 target
+func setArg(target reflect.Value, arg string) error {
+	if unmarshaler, ok := target.Addr().Interface().(encoding.TextUnmarshaler); ok {
+		err := unmarshaler.UnmarshalText([]byte(arg))
+		if err != nil {
+			return errors.New("无效: " + err.Error())
+		}
+		return nil
+	}
+
+	if target.Type() == durationType {
+		duration, err := time.ParseDuration(arg)
+		if err != nil {
+			return errors.New("应为时长, 如 10m")
+		}
+		target.SetInt(int64(duration))
+		return nil
+	}
+
+	switch target.Kind() {
+	case reflect.String:
+		target.SetString(arg)
+	case reflect.Bool:
+		b, err := strconv.ParseBool(arg)
+		if err != nil {
+			return errors.New("应为 true 或 false")
+		}
+		target.SetBool(b)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		i, err := strconv.ParseInt(arg, 10, target.Type().Bits())
+		if err != nil {
+			return errors.New("应为整数")
+		}
+		target.SetInt(i)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		u, err := strconv.ParseUint(arg, 10, target.Type().Bits())
+		if err != nil {
+			return errors.New("应为非负整数")
+		}
+		target.SetUint(u)
+	case reflect.Float32, reflect.Float64:
+		f, err := strconv.ParseFloat(arg, target.Type().Bits())
+		if err != nil {
+			return errors.New("应为数字")
+		}
+		target.SetFloat(f)
+	default:
+		return errors.New("类型不支持: " + target.Type().String())
+	}
+	return nil
+}
+
+// argsUsage 由参数结构体生成用法, 必需参数为 <name>, 可选参数为 [name], 剩余参数以 ... 结尾
+func argsUsage(typ reflect.Type) string {
+	if typ.Kind() != reflect.Struct {
+		return ""
+	}
+
+	fields, err := argFields(typ)
+	if err != nil {
+		return ""
+	}
+
+	usage := make([]string, 0)
+	for _, field := range fields {
+		name := field.name
+		if field.rest {
+			name += "..."
+		}
+		if field.optional {
+			usage = append(usage, "["+name+"]")
+		} else {
+			usage = append(usage, "<"+name+">")
+		}
+	}
+	return strings.Join(usage, " ")
+}
@@ -0,0 +1,145 @@
+package hareru_cq
+
+import (
+	"errors"
+	"net"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestSplitArgs(t *testing.T) {
+	tests := []struct {
+		text string
+		want []string
+	}{
+		{"", []string{}},
+		{"  a  b\tc\n", []string{"a", "b", "c"}},
+		{`"a b" c`, []string{"a b", "c"}},
+		{`'a "b"' c`, []string{`a "b"`, "c"}},
+		{`a"b c"d`, []string{"ab cd"}},
+		{`""`, []string{""}},
+		{"“你 好” ‘世 界’", []string{"你 好", "世 界"}},
+		{"“a\"b”", []string{`a"b`}},
+		{`a\ b \"c\" \\`, []string{"a b", `"c"`, `\`}},
+		{`"a \" b"`, []string{`a " b`}},
+		{`tail\`, []string{`tail\`}},
+		{`"unclosed quote  x`, []string{"unclosed quote  x"}},
+		{"a “unclosed", []string{"a", "unclosed"}},
+	}
+
+	for _, tt := range tests {
+		if got := splitArgs(tt.text); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("splitArgs(%q) = %q, want %q", tt.text, got, tt.want)
+		}
+	}
+}
+
+type bindValidated struct {
+	Count int
+}
+
+func (v *bindValidated) Validate() error {
+	if v.Count > 10 {
+		return &ArgumentErr{Argument: "count", Message: "过大"}
+	}
+	return nil
+}
+
+func TestBind(t *testing.T) {
+	type basic struct {
+		Name    string
+		Count   int     `arg:"数量"`
+		Ratio   float64 `arg:",optional"`
+		Enabled bool    `arg:"enabled,optional"`
+		skipped string
+		Ignored string   `arg:"-"`
+		Tags    []string `arg:"tags"`
+	}
+	type rest struct {
+		Id   uint8
+		Text string `arg:"text,rest"`
+	}
+	type typed struct {
+		Timeout time.Duration
+		Ip      net.IP
+		Ids     []int64
+	}
+	type sliceFirst struct {
+		Ids  []int64
+		Name string
+	}
+	type restFirst struct {
+		Text string `arg:"text,rest"`
+		Name string
+	}
+
+	tests := []struct {
+		name    string
+		args    []string
+		target  any
+		want    any
+		wantErr string
+	}{
+		{"all fields", []string{"a", "3", "0.5", "true", "x", "y"}, &basic{},
+			&basic{Name: "a", Count: 3, Ratio: 0.5, Enabled: true, Tags: []string{"x", "y"}}, ""},
+		{"optional missing", []string{"a", "3"}, &basic{}, &basic{Name: "a", Count: 3}, ""},
+		{"required missing", []string{"a"}, &basic{}, nil, "数量 缺失"},
+		{"invalid int", []string{"a", "x"}, &basic{}, nil, "数量 应为整数"},
+		{"invalid bool", []string{"a", "1", "1", "maybe"}, &basic{}, nil, "enabled 应为 true 或 false"},
+		{"rest string", []string{"7", "hello", "world"}, &rest{}, &rest{Id: 7, Text: "hello world"}, ""},
+		{"rest missing", []string{"7"}, &rest{}, nil, "text 缺失"},
+		{"uint overflow", []string{"256", "x"}, &rest{}, nil, "id 应为非负整数"},
+		{"typed", []string{"1m30s", "127.0.0.1", "1", "2"}, &typed{},
+			&typed{Timeout: 90 * time.Second, Ip: net.ParseIP("127.0.0.1"), Ids: []int64{1, 2}}, ""},
+		{"invalid duration", []string{"10", "127.0.0.1"}, &typed{}, nil, "timeout 应为时长"},
+		{"invalid text unmarshaler", []string{"1s", "x"}, &typed{}, nil, "ip 无效"},
+		{"invalid slice element", []string{"1s", "127.0.0.1", "1", "x"}, &typed{}, nil, "ids 应为整数"},
+		{"too many", []string{"1", "2"}, &bindValidated{}, nil, "参数过多: 2"},
+		{"validate", []string{"11"}, &bindValidated{}, nil, "count 过大"},
+		{"validate ok", []string{"5"}, &bindValidated{}, &bindValidated{Count: 5}, ""},
+		{"slice not last", []string{"1", "a"}, &sliceFirst{}, nil, "ids 剩余参数与切片字段须为最后一个字段"},
+		{"rest not last", []string{"a", "b"}, &restFirst{}, nil, "text 剩余参数与切片字段须为最后一个字段"},
+		{"not a pointer", nil, basic{}, nil, "绑定目标应为结构体指针"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := (&CommandContext{Args: tt.args}).Bind(tt.target)
+			if tt.wantErr != "" {
+				var argErr *ArgumentErr
+				if !errors.As(err, &argErr) || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("err = %v, want ArgumentErr containing %q", err, tt.wantErr)
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(tt.target, tt.want) {
+				t.Errorf("Bind() = %+v, want %+v", tt.target, tt.want)
+			}
+		})
+	}
+}
+
+func TestArgsUsage(t *testing.T) {
+	type args struct {
+		UserId   int64         `arg:"qq"`
+		Duration time.Duration `arg:"时长,optional"`
+		Reason   []string      `arg:"理由"`
+	}
+	type invalid struct {
+		Ids  []int64
+		Name string
+	}
+
+	if got := argsUsage(reflect.TypeOf(args{})); got != "<qq> [时长] [理由...]" {
+		t.Errorf("argsUsage() = %q", got)
+	}
+	if got := argsUsage(reflect.TypeOf(invalid{})); got != "" {
+		t.Errorf("argsUsage(invalid) = %q, want empty", got)
+	}
+}
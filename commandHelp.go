@@ -0,0 +1,203 @@
+package hareru_cq
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// DefaultHelpPageSize 帮助列表默认每页命令数
+const DefaultHelpPageSize = 10
+
+// otherCategory 未设置分类的命令在帮助列表中的分类名
+const otherCategory = "其他"
+
+// UsageLine 命令用法, 如 "/ban <qq> [时长]"
+// prefix string 显示的命令前缀
+func (h *CommandHandler) UsageLine(prefix string) string {
+	if h.Usage == "" {
+		return prefix + h.Command
+	}
+	return prefix + h.Command + " " + h.Usage
+}
+
+// HelpText 命令的详细帮助
+// prefix string 显示的命令前缀
+func (h *CommandHandler) HelpText(prefix string) string {
+	var builder strings.Builder
+	builder.WriteString(h.UsageLine(prefix))
+	if h.Description != "" {
+		builder.WriteString("\n" + h.Description)
+	}
+	if len(h.Aliases) > 0 {
+		builder.WriteString("\n别名: " + strings.Join(h.Aliases, ", "))
+	}
+	if len(h.Examples) > 0 {
+		builder.WriteString("\n示例:")
+		for _, example := range h.Examples {
+			builder.WriteString("\n  " + prefix + example)
+		}
+	}
+	return builder.String()
+}
+
+// newHelpCommand 构建帮助命令
+// help 列出第一页, help <页码> 翻页, help <命令> 查看命令详细帮助
+func (r *CommandRouter) newHelpCommand() *CommandHandler {
+	help := NewCommandHandler("help", r.help)
+	help.Aliases = []string{"帮助"}
+	help.Usage = "[命令|页码]"
+	help.Description = "查看命令列表或命令的详细用法"
+	return &help
+}
+
+// help 帮助命令的处理函数
+func (r *CommandRouter) help(c *CommandContext) any {
+	prefix := c.Prefix
+	if prefix == "" && len(r.Prefixes) > 0 {
+		prefix = r.Prefixes[0]
+	}
+
+	arg := c.Arg(0)
+	page, err := strconv.Atoi(arg)
+	if arg != "" && err != nil {
+		r.reply(c, r.commandHelp(c.Update, prefix, arg))
+		return nil
+	}
+
+	pages := r.helpPages(c.Update, prefix)
+	if arg == "" {
+		page = 1
+	}
+
+	if arg == "" && r.HelpForward && len(pages) > 1 {
+		err = r.sendHelpForward(c, pages)
+		if err == nil {
+			return nil
+		}
+		log.Error("发送合并转发帮助失败, 改为分页发送:", err)
+	}
+
+	if page < 1 || page > len(pages) {
+		r.reply(c, fmt.Sprintf("页码超出范围 (共 %d 页)", len(pages)))
+		return nil
+	}
+	r.reply(c, pages[page-1])
+	return nil
+}
+
+// reply 以纯文本回复帮助信息, 其中可能包含用户输入
+func (r *CommandRouter) reply(c *CommandContext, text string) {
+	_, err := c.Message.Reply(text, ReplyOptions{Quote: true, AutoEscape: true})
+	if err != nil {
+		log.Error("回复帮助信息失败:", err)
+	}
+}
+
+// commandHelp 命令的详细帮助, 命令不存在或无权执行时提示未找到
+func (r *CommandRouter) commandHelp(update *Update, prefix string, name string) string {
+	name = strings.TrimPrefix(name, prefix)
+	command := r.Find(name)
+	if command == nil || !command.Permitted(update) {
+		return "未找到命令: " + name
+	}
+	return command.HelpText(prefix)
+}
+
+// visibleCommands 按分类分组的可见命令, 仅包含 update 触发者有权执行的命令
+func (r *CommandRouter) visibleCommands(update *Update) ([]string, map[string][]*CommandHandler) {
+	categories := make([]string, 0)
+	grouped := make(map[string][]*CommandHandler)
+	for _, command := range r.Commands() {
+		if command.Hidden || !command.Permitted(update) {
+			continue
+		}
+
+		category := command.Category
+		if category == "" {
+			category = otherCategory
+		}
+		if _, ok := grouped[category]; !ok {
+			categories = append(categories, category)
+		}
+		grouped[category] = append(grouped[category], command)
+	}
+
+	// 未分类的命令排在最后
+	sort.SliceStable(categories, func(i, j int) bool {
+		if categories[i] == otherCategory || categories[j] == otherCategory {
+			return categories[j] == otherCategory && categories[i] != otherCategory
+		}
+		return categories[i] < categories[j]
+	})
+	return categories, grouped
+}
+
+// helpPages 分页的命令列表, 至少一页
+func (r *CommandRouter) helpPages(update *Update, prefix string) []string {
+	pageSize := r.HelpPageSize
+	if pageSize <= 0 {
+		pageSize = DefaultHelpPageSize
+	}
+
+	lines := make([][]string, 0) // 每页的行
+	page := make([]string, 0)
+	count := 0
+	categories, grouped := r.visibleCommands(update)
+	for _, category := range categories {
+		for i, command := range grouped[category] {
+			if count == pageSize {
+				lines = append(lines, page)
+				page = make([]string, 0)
+				count = 0
+			}
+			if i == 0 || len(page) == 0 {
+				page = append(page, "["+category+"]")
+			}
+
+			line := command.UsageLine(prefix)
+			if command.Description != "" {
+				line += " - " + command.Description
+			}
+			page = append(page, line)
+			count++
+		}
+	}
+	lines = append(lines, page)
+
+	pages := make([]string, len(lines))
+	for i, page := range lines {
+		header := fmt.Sprintf("命令列表 (%d/%d)", i+1, len(lines))
+		footer := fmt.Sprintf("发送 %shelp <命令> 查看详细用法", prefix)
+		if len(lines) > 1 {
+			footer += fmt.Sprintf(", %shelp <页码> 翻页", prefix)
+		}
+		pages[i] = header + "\n" + strings.Join(page, "\n") + "\n" + footer
+	}
+	return pages
+}
+
+// sendHelpForward 以合并转发发送全部帮助页
+func (r *CommandRouter) sendHelpForward(c *CommandContext, pages []string) error {
+	name, userId := "help", int64(0)
+	if info := c.Bot.Info(); info != nil {
+		name, userId = info.NickName, info.UserId
+	}
+
+	// 与分页回复一致, 帮助文本作为纯文本发送
+	forward := NewForward()
+	for _, page := range pages {
+		forward.Node(name, userId, NewMessage().Text(page))
+	}
+
+	var err error
+	if c.Message.IsGroupMessage() {
+		_, err = c.Bot.SendGroupForwardMessage(c.Message.GroupId, forward)
+	} else {
+		_, err = c.Bot.SendPrivateForwardMessage(c.Message.Sender.UserId, forward)
+	}
+	return err
+}
@@ -0,0 +1,30 @@
+package hareru_cq
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestHelpForwardSendsPlainText(t *testing.T) {
+	fake := newFakeCqHttp(t)
+	bot := newTestBot(t, fake)
+
+	router := NewCommandRouter("/")
+	router.HelpPageSize = 1
+	router.HelpForward = true
+	router.Command("a", func(c *CommandContext) any { return nil }).Description = "a & b [CQ:at,qq=all]"
+	router.Command("b", func(c *CommandContext) any { return nil })
+
+	update := newMessageUpdate(t, "/help")
+	update.Bot = bot
+	router.HandleUpdate(update)
+
+	request := fake.waitRequest(t, "send_group_forward_msg")
+	content := request.Get("params.messages.0.data.content")
+	if !content.IsArray() || content.Get("#").Int() != 1 || content.Get("0.type").String() != TextSegment {
+		t.Fatalf("node content = %s, want a single text segment", content.Raw)
+	}
+	if text := content.Get("0.data.text").String(); !strings.Contains(text, "a & b [CQ:at,qq=all]") {
+		t.Errorf("node text = %q", text)
+	}
+}
@@ -0,0 +1,93 @@
+package hareru_cq
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/tidwall/gjson"
+)
+
+// newMessageUpdate 由 CQ 码消息构造群消息事件, self_id 为 10
+func newMessageUpdate(t *testing.T, message string) *Update {
+	return newEventUpdate(t, map[string]any{
+		"post_type":    "message",
+		"message_type": "group",
+		"self_id":      10,
+		"group_id":     100,
+		"user_id":      1,
+		"message":      message,
+	})
+}
+
+// newEventUpdate 由事件数据构造 Update, 与 Updater 的解析方式一致
+func newEventUpdate(t *testing.T, data map[string]any) *Update {
+	raw, err := json.Marshal(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	event := &Event{}
+	err = json.Unmarshal(raw, event)
+	if err != nil {
+		t.Fatal(err)
+	}
+	event.Json = gjson.ParseBytes(raw)
+
+	return &Update{Event: event}
+}
+
+func TestParseCommand(t *testing.T) {
+	tests := []struct {
+		message string
+		ok      bool
+		name    string
+		args    []string
+	}{
+		{"/echo a b", true, "echo", []string{"a", "b"}},
+		{"hello", false, "", nil},
+		{"/", false, "", nil},
+		{"[CQ:reply,id=1][CQ:at,qq=10] /echo", true, "echo", []string{}},
+		{"[CQ:at,qq=10] echo x", true, "echo", []string{"x"}},
+		{"[CQ:at,qq=20] /echo", false, "", nil},
+		{"/ban [CQ:at,qq=123] 10m", true, "ban", []string{"123", "10m"}},
+		{"/ban[CQ:at,qq=123][CQ:at,qq=456]", true, "ban", []string{"123", "456"}},
+		{"/echo [CQ:image,file=a.png]a", true, "echo", []string{"a"}},
+	}
+
+	for _, tt := range tests {
+		command, ok := parseCommand(newMessageUpdate(t, tt.message), DefaultCommandPrefixes, true)
+		if ok != tt.ok {
+			t.Errorf("parseCommand(%q) ok = %v, want %v", tt.message, ok, tt.ok)
+			continue
+		}
+		if !ok {
+			continue
+		}
+		if command.Name != tt.name || !reflect.DeepEqual(command.Args, tt.args) {
+			t.Errorf("parseCommand(%q) = %q %q, want %q %q", tt.message, command.Name, command.Args, tt.name, tt.args)
+		}
+	}
+}
+
+func TestBindMention(t *testing.T) {
+	type BanArgs struct {
+		UserId   int64         `arg:"qq"`
+		Duration time.Duration `arg:"时长,optional"`
+	}
+
+	command, ok := parseCommand(newMessageUpdate(t, "/ban [CQ:at,qq=123] 10m"), DefaultCommandPrefixes, false)
+	if !ok {
+		t.Fatal("command not parsed")
+	}
+
+	var args BanArgs
+	err := command.Bind(&args)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if args.UserId != 123 || args.Duration != 10*time.Minute {
+		t.Errorf("Bind() = %+v", args)
+	}
+}
@@ -1,16 +1,23 @@
 package hareru_cq
 
 import (
+	"context"
 	"fmt"
 )
 
 // ActionFailErr occurred when the action failed
 type ActionFailErr struct {
+	Action  string // 失败的 Action
+	RetCode int    // cqhttp 返回的 retcode
+	Wording string // cqhttp 返回的错误说明
 	Message string
 }
 
 func (e *ActionFailErr) Error() string {
-	return fmt.Sprintf("Action失败: %s", e.Message)
+	if e.Action == "" {
+		return fmt.Sprintf("Action失败: %s", e.Message)
+	}
+	return fmt.Sprintf("Action失败: %s (retcode %d): %s", e.Action, e.RetCode, e.Message)
 }
 
 // NotAvailableErr occurred when the method is not available
@@ -38,3 +45,70 @@ type AlreadyRunningErr struct {
 func (e *AlreadyRunningErr) Error() string {
 	return fmt.Sprintf("AlreadyRunningErr: %s", e.Message)
 }
+
+// AuthFailedErr occurred when cqhttp rejects the access token
+type AuthFailedErr struct {
+	StatusCode int
+	Message    string
+}
+
+func (e *AuthFailedErr) Error() string {
+	return fmt.Sprintf("AccessToken 认证失败 (%d): %s", e.StatusCode, e.Message)
+}
+
+// ConnectionLostErr occurred when the connection to cqhttp is lost
+type ConnectionLostErr struct {
+	Message string
+}
+
+func (e *ConnectionLostErr) Error() string {
+	return fmt.Sprintf("Connection lost: %s", e.Message)
+}
+
+// ActionTimeoutErr occurred when cqhttp does not respond to the action in time
+type ActionTimeoutErr struct {
+	Action string
+}
+
+func (e *ActionTimeoutErr) Error() string {
+	return fmt.Sprintf("Action超时: %s", e.Action)
+}
+
+func (e *ActionTimeoutErr) Unwrap() error {
+	return context.DeadlineExceeded
+}
+
+// InvalidMessageErr occurred when the message content is not supported
+type InvalidMessageErr struct {
+	Message string
+}
+
+func (e *InvalidMessageErr) Error() string {
+	return fmt.Sprintf("Invalid message: %s", e.Message)
+}
+
+// MediaTooLargeErr occurred when the media exceeds the size limit
+type MediaTooLargeErr struct {
+	Size  int64 // 实际大小, 由 io.Reader 读取时未读完全部数据, 大小未知, 为 0
+	Limit int64
+}
+
+func (e *MediaTooLargeErr) Error() string {
+	if e.Size == 0 {
+		return fmt.Sprintf("Media too large: exceeds limit %d bytes", e.Limit)
+	}
+	return fmt.Sprintf("Media too large: %d bytes (limit %d)", e.Size, e.Limit)
+}
+
+// ArgumentErr occurred when the command arguments fail to bind or validate
+type ArgumentErr struct {
+	Argument string // 参数名, 为空时表示整体错误
+	Message  string
+}
+
+func (e *ArgumentErr) Error() string {
+	if e.Argument == "" {
+		return fmt.Sprintf("参数错误: %s", e.Message)
+	}
+	return fmt.Sprintf("参数错误: %s %s", e.Argument, e.Message)
+}
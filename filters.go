@@ -1,14 +1,73 @@
 package hareru_cq
 
+// Filter 事件过滤器
 type Filter interface {
-	Filter(args ...any) bool
+	Filter(update *Update) bool
 }
 
+// FilterFunc 由函数构建的过滤器
+//
+//	filter := NewEventFilter(GroupMessageEvent).And(FilterFunc(func(update *Update) bool {
+//		return update.Event.Message().Has(ImageSegment)
+//	}))
+type FilterFunc func(update *Update) bool
+
+func (f FilterFunc) Filter(update *Update) bool {
+	return f(update)
+}
+
+// And 与 filters 全部通过时通过
+func (f FilterFunc) And(filters ...Filter) FilterFunc {
+	return And(append([]Filter{f}, filters...)...)
+}
+
+// Or 与 filters 任一通过时通过
+func (f FilterFunc) Or(filters ...Filter) FilterFunc {
+	return Or(append([]Filter{f}, filters...)...)
+}
+
+// Not 取反
+func (f FilterFunc) Not() FilterFunc {
+	return Not(f)
+}
+
+// And 全部过滤器通过时通过, 无过滤器时通过
+func And(filters ...Filter) FilterFunc {
+	return func(update *Update) bool {
+		for _, filter := range filters {
+			if !filter.Filter(update) {
+				return false
+			}
+		}
+		return true
+	}
+}
+
+// Or 任一过滤器通过时通过, 无过滤器时不通过
+func Or(filters ...Filter) FilterFunc {
+	return func(update *Update) bool {
+		for _, filter := range filters {
+			if filter.Filter(update) {
+				return true
+			}
+		}
+		return false
+	}
+}
+
+// Not 过滤器不通过时通过
+func Not(filter Filter) FilterFunc {
+	return func(update *Update) bool {
+		return !filter.Filter(update)
+	}
+}
+
+// EventFilter 事件类型过滤器, 匹配 EventTypes 中任一事件类型时通过
 type EventFilter struct {
-	filterMap map[string]func(update *Update, eventType string) bool
+	EventTypes []string // 事件类型, 如 GroupMessageEvent, PokeEvent, 为空时全部通过
 }
 
-func (f *EventFilter) messageFilter(update *Update, eventType string) bool {
+func messageFilter(update *Update, eventType string) bool {
 	if update.Event.Type == "message" {
 		if eventType == ReceiveMessageEvent {
 			return true
@@ -24,7 +83,7 @@ func (f *EventFilter) messageFilter(update *Update, eventType string) bool {
 	return false
 }
 
-func (f *EventFilter) requestFilter(update *Update, eventType string) bool {
+func requestFilter(update *Update, eventType string) bool {
 	if update.Event.Type == "request" {
 		reqType := update.Event.Get("request_type").String()
 
@@ -36,7 +95,7 @@ func (f *EventFilter) requestFilter(update *Update, eventType string) bool {
 	return false
 }
 
-func (f *EventFilter) noticeFilter(update *Update, eventType string) bool {
+func noticeFilter(update *Update, eventType string) bool {
 	if update.Event.Type == "notice" {
 		noticeType := update.Event.Get("notice_type").String()
 
@@ -49,7 +108,16 @@ func (f *EventFilter) noticeFilter(update *Update, eventType string) bool {
 	return false
 }
 
-func (f *EventFilter) metaEventFilter(update *Update, eventType string) bool {
+// notifyFilter 按 sub_type 匹配 notify 通知, NotifyEvent 匹配所有子类型
+func notifyFilter(update *Update, eventType string) bool {
+	if update.Event.Type == "notice" && update.Event.Get("notice_type").String() == NotifyEvent {
+		return eventType == NotifyEvent || update.Event.SubType == eventType
+	}
+
+	return false
+}
+
+func metaEventFilter(update *Update, eventType string) bool {
 	if update.Event.Type == "meta_event" {
 		metaEventType := update.Event.Get("meta_event_type").String()
 
@@ -61,33 +129,93 @@ func (f *EventFilter) metaEventFilter(update *Update, eventType string) bool {
 	return false
 }
 
-func (f *EventFilter) Filter(update *Update, eventType string) bool {
-	f.filterMap = map[string]func(update *Update, eventType string) bool{
-		ReceiveMessageEvent: f.messageFilter,
-		PrivateMessageEvent: f.messageFilter,
-		GroupMessageEvent:   f.messageFilter,
-
-		FriendRecallEvent:  f.noticeFilter,
-		GroupRecallEvent:   f.noticeFilter,
-		GroupIncreaseEvent: f.noticeFilter,
-		GroupDecreaseEvent: f.noticeFilter,
-		GroupAdminEvent:    f.noticeFilter,
-		GroupUploadEvent:   f.noticeFilter,
-		GroupBanEvent:      f.noticeFilter,
-		FriendAddEvent:     f.noticeFilter,
-		GroupCardEvent:     f.noticeFilter,
-		EssenceEvent:       f.noticeFilter,
-
-		FriendRequestEvent: f.requestFilter,
-		GroupRequestEvent:  f.requestFilter,
-
-		LifecycleEvent: f.metaEventFilter,
-		HeartbeatEvent: f.metaEventFilter,
+func connectionFilter(update *Update, eventType string) bool {
+	if update.Event.Type == "meta_event" && update.Event.Get("meta_event_type").String() == ConnectionMetaEvent {
+		return update.Event.SubType == eventType
+	}
+
+	return false
+}
+
+// eventFilterMap 事件类型对应的匹配函数
+var eventFilterMap = map[string]func(update *Update, eventType string) bool{
+	ReceiveMessageEvent: messageFilter,
+	PrivateMessageEvent: messageFilter,
+	GroupMessageEvent:   messageFilter,
+
+	FriendRecallEvent:  noticeFilter,
+	GroupRecallEvent:   noticeFilter,
+	GroupIncreaseEvent: noticeFilter,
+	GroupDecreaseEvent: noticeFilter,
+	GroupAdminEvent:    noticeFilter,
+	GroupUploadEvent:   noticeFilter,
+	GroupBanEvent:      noticeFilter,
+	FriendAddEvent:     noticeFilter,
+	GroupCardEvent:     noticeFilter,
+	EssenceEvent:       noticeFilter,
+	ClientStatusEvent:  noticeFilter,
+	OfflineFileEvent:   noticeFilter,
+
+	NotifyEvent:    notifyFilter,
+	PokeEvent:      notifyFilter,
+	LuckyKingEvent: notifyFilter,
+	HonorEvent:     notifyFilter,
+	TitleEvent:     notifyFilter,
+
+	FriendRequestEvent: requestFilter,
+	GroupRequestEvent:  requestFilter,
+
+	LifecycleEvent: metaEventFilter,
+	HeartbeatEvent: metaEventFilter,
+
+	ConnectEvent:    connectionFilter,
+	DisconnectEvent: connectionFilter,
+	AuthFailedEvent: connectionFilter,
+}
+
+func (f *EventFilter) Filter(update *Update) bool {
+	if len(f.EventTypes) == 0 {
+		return true
+	}
+
+	for _, eventType := range f.EventTypes {
+		if f.Match(update, eventType) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Match 事件是否为 eventType 类型, 未知的事件类型不匹配
+func (f *EventFilter) Match(update *Update, eventType string) bool {
+	filter, ok := eventFilterMap[eventType]
+	if !ok {
+		return false
 	}
 
-	return f.filterMap[eventType](update, eventType)
+	return filter(update, eventType)
+}
+
+// And 与 filters 全部通过时通过
+func (f *EventFilter) And(filters ...Filter) FilterFunc {
+	return And(append([]Filter{f}, filters...)...)
+}
+
+// Or 与 filters 任一通过时通过
+func (f *EventFilter) Or(filters ...Filter) FilterFunc {
+	return Or(append([]Filter{f}, filters...)...)
 }
 
-func NewEventFilter() EventFilter {
-	return EventFilter{}
+// Not 取反
+func (f *EventFilter) Not() FilterFunc {
+	return Not(f)
+}
+
+// NewEventFilter 构建事件类型过滤器
+// eventTypes ...string 事件类型, 匹配任一即通过
+func NewEventFilter(eventTypes ...string) *EventFilter {
+	return &EventFilter{
+		EventTypes: eventTypes,
+	}
 }
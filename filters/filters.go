@@ -0,0 +1,122 @@
+// Package filters 常用的事件过滤器, 可与 hareru_cq.And / Or / Not 组合使用
+//
+//	handler := &hareru_cq.MessageHandler{
+//		Filter: filters.InGroups(123456).And(filters.Role(hareru_cq.GroupAdminRole), filters.HasImage()),
+//		Callback: callback,
+//	}
+package filters
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/QDis233/hareru_cq"
+)
+
+// contains ids 中是否包含 id
+func contains(ids []int64, id int64) bool {
+	for _, v := range ids {
+		if v == id {
+			return true
+		}
+	}
+	return false
+}
+
+// Group 群消息
+func Group() hareru_cq.FilterFunc {
+	return hareru_cq.NewEventFilter(hareru_cq.GroupMessageEvent).Filter
+}
+
+// Private 私聊消息
+func Private() hareru_cq.FilterFunc {
+	return hareru_cq.NewEventFilter(hareru_cq.PrivateMessageEvent).Filter
+}
+
+// InGroups 来自指定群的事件, 包括群消息, 群通知与加群请求
+func InGroups(groupIds ...int64) hareru_cq.FilterFunc {
+	return func(update *hareru_cq.Update) bool {
+		groupId := update.Event.Get("group_id")
+		return groupId.Exists() && contains(groupIds, groupId.Int())
+	}
+}
+
+// FromUsers 由指定用户触发的事件, 即 user_id 为 userIds 之一
+func FromUsers(userIds ...int64) hareru_cq.FilterFunc {
+	return func(update *hareru_cq.Update) bool {
+		userId := update.Event.Get("user_id")
+		return userId.Exists() && contains(userIds, userId.Int())
+	}
+}
+
+// FromSelf Bot 自己发送的消息, 包括 message_sent 上报, 不匹配 Bot 自身触发的通知与请求
+func FromSelf() hareru_cq.FilterFunc {
+	return func(update *hareru_cq.Update) bool {
+		if update.Event.Type != "message" && update.Event.Type != "message_sent" {
+			return false
+		}
+
+		userId := update.Event.Get("user_id")
+		return userId.Exists() && userId.Int() == update.Event.Get("self_id").Int()
+	}
+}
+
+// Superuser 由超级用户触发的事件, 超级用户通过 ApplicationBuilder.Superusers 设置
+func Superuser() hareru_cq.FilterFunc {
+	return func(update *hareru_cq.Update) bool {
+		userId := update.Event.Get("user_id")
+		return userId.Exists() && update.Bot.IsSuperuser(userId.Int())
+	}
+}
+
+// Role 发送者群角色为 roles 之一的群消息
+// roles ...string GroupOwnerRole, GroupAdminRole 或 GroupMemberRole
+func Role(roles ...string) hareru_cq.FilterFunc {
+	return func(update *hareru_cq.Update) bool {
+		role := update.Event.Get("sender.role").String()
+		for _, r := range roles {
+			if role == r {
+				return true
+			}
+		}
+		return false
+	}
+}
+
+// MentionsBot @ 了 Bot 的消息
+func MentionsBot() hareru_cq.FilterFunc {
+	return func(update *hareru_cq.Update) bool {
+		return update.Event.Message().Mentioned(update.Event.Get("self_id").Int())
+	}
+}
+
+// IsReply 回复其他消息的消息
+func IsReply() hareru_cq.FilterFunc {
+	return func(update *hareru_cq.Update) bool {
+		_, ok := update.Event.Message().ReplyId()
+		return ok
+	}
+}
+
+// HasImage 包含图片的消息
+func HasImage() hareru_cq.FilterFunc {
+	return func(update *hareru_cq.Update) bool {
+		return update.Event.Message().Has(hareru_cq.ImageSegment)
+	}
+}
+
+// Regex 纯文本内容匹配正则表达式的消息, pattern 无效时 panic
+func Regex(pattern string) hareru_cq.FilterFunc {
+	re := regexp.MustCompile(pattern)
+	return func(update *hareru_cq.Update) bool {
+		return update.Event.Type == "message" && re.MatchString(update.Event.Message().PlainText())
+	}
+}
+
+// Prefix 纯文本内容 (忽略开头空白) 以 prefix 开头的消息
+func Prefix(prefix string) hareru_cq.FilterFunc {
+	return func(update *hareru_cq.Update) bool {
+		text := strings.TrimSpace(update.Event.Message().PlainText())
+		return update.Event.Type == "message" && strings.HasPrefix(text, prefix)
+	}
+}
@@ -0,0 +1,169 @@
+package filters
+
+import (
+	"encoding/json"
+	"strconv"
+	"testing"
+
+	"github.com/QDis233/hareru_cq"
+	"github.com/tidwall/gjson"
+)
+
+// newUpdate 由事件数据构造 Update, Bot 的超级用户为 1
+func newUpdate(t *testing.T, data map[string]any) *hareru_cq.Update {
+	raw, err := json.Marshal(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	event := &hareru_cq.Event{}
+	err = json.Unmarshal(raw, event)
+	if err != nil {
+		t.Fatal(err)
+	}
+	event.Json = gjson.ParseBytes(raw)
+
+	return &hareru_cq.Update{
+		Bot:   &hareru_cq.Bot{Superusers: []int64{1}},
+		Event: event,
+	}
+}
+
+// arrayMessage 将 CQ 码转换为数组格式的消息, 数字参数与 cqhttp 一致以数字上报
+func arrayMessage(message string) []map[string]any {
+	array := make([]map[string]any, 0)
+	for _, seg := range hareru_cq.ParseCQString(message) {
+		data := make(map[string]any, len(seg.Data))
+		for key := range seg.Data {
+			value := seg.Get(key)
+			if _, err := strconv.ParseInt(value, 10, 64); err == nil && seg.Type != hareru_cq.TextSegment {
+				data[key] = json.Number(value)
+			} else {
+				data[key] = value
+			}
+		}
+		array = append(array, map[string]any{"type": seg.Type, "data": data})
+	}
+	return array
+}
+
+// groupMessage 用户 1 在群 100 中发送的消息, self_id 为 10
+func groupMessage(message any, extra map[string]any) map[string]any {
+	data := map[string]any{
+		"post_type":    "message",
+		"message_type": "group",
+		"self_id":      10,
+		"group_id":     100,
+		"user_id":      1,
+		"sender":       map[string]any{"user_id": 1, "role": hareru_cq.GroupMemberRole},
+		"message":      message,
+	}
+	for key, value := range extra {
+		data[key] = value
+	}
+	return data
+}
+
+func TestMessageFilters(t *testing.T) {
+	admin := map[string]any{"sender": map[string]any{"user_id": 1, "role": hareru_cq.GroupAdminRole}}
+	private := map[string]any{"message_type": "private", "group_id": nil}
+
+	tests := []struct {
+		name    string
+		filter  hareru_cq.FilterFunc
+		message string
+		extra   map[string]any
+		want    bool
+	}{
+		{"group", Group(), "hi", nil, true},
+		{"group private", Group(), "hi", private, false},
+		{"private", Private(), "hi", private, true},
+		{"in groups", InGroups(1, 100), "hi", nil, true},
+		{"in other groups", InGroups(200), "hi", nil, false},
+		{"in groups private", InGroups(100), "hi", private, false},
+		{"from users", FromUsers(1), "hi", nil, true},
+		{"from other users", FromUsers(2, 3), "hi", nil, false},
+		{"from self", FromSelf(), "hi", map[string]any{"user_id": 10}, true},
+		{"from self sent", FromSelf(), "hi", map[string]any{"post_type": "message_sent", "user_id": 10}, true},
+		{"not from self", FromSelf(), "hi", nil, false},
+		{"superuser", Superuser(), "hi", nil, true},
+		{"not superuser", Superuser(), "hi", map[string]any{"user_id": 2}, false},
+		{"role", Role(hareru_cq.GroupOwnerRole, hareru_cq.GroupAdminRole), "hi", admin, true},
+		{"role member", Role(hareru_cq.GroupAdminRole), "hi", nil, false},
+		{"mentions bot", MentionsBot(), "[CQ:at,qq=10] hi", nil, true},
+		{"mentions other", MentionsBot(), "[CQ:at,qq=11] hi", nil, false},
+		{"mentions all", MentionsBot(), "[CQ:at,qq=all] hi", nil, false},
+		{"reply", IsReply(), "[CQ:reply,id=5]hi", nil, true},
+		{"not reply", IsReply(), "hi", nil, false},
+		{"image", HasImage(), "look [CQ:image,file=a.png]", nil, true},
+		{"no image", HasImage(), "[CQ:face,id=1]", nil, false},
+		{"regex", Regex(`^\d+$`), "[CQ:at,qq=10]123", nil, true},
+		{"regex mismatch", Regex(`^\d+$`), "a1", nil, false},
+		{"regex escaped", Regex(`^\[x\]$`), "&#91;x&#93;", nil, true},
+		{"prefix", Prefix("/"), "  /cmd", nil, true},
+		{"prefix after reply", Prefix("/"), "[CQ:reply,id=1] /cmd", nil, true},
+		{"prefix mismatch", Prefix("/"), "cmd /", nil, false},
+	}
+
+	for _, tt := range tests {
+		formats := map[string]any{
+			"string": tt.message,
+			"array":  arrayMessage(tt.message),
+		}
+		for format, message := range formats {
+			t.Run(tt.name+"/"+format, func(t *testing.T) {
+				update := newUpdate(t, groupMessage(message, tt.extra))
+				if got := tt.filter(update); got != tt.want {
+					t.Errorf("filter = %v, want %v", got, tt.want)
+				}
+			})
+		}
+	}
+}
+
+func TestFiltersOnNotices(t *testing.T) {
+	increase := map[string]any{
+		"post_type":   "notice",
+		"notice_type": "group_increase",
+		"self_id":     10,
+		"group_id":    100,
+		"user_id":     10,
+	}
+
+	tests := []struct {
+		name   string
+		filter hareru_cq.FilterFunc
+		want   bool
+	}{
+		{"in groups", InGroups(100), true},
+		{"from users", FromUsers(10), true},
+		{"from self", FromSelf(), false},
+		{"group", Group(), false},
+		{"mentions bot", MentionsBot(), false},
+		{"regex", Regex(".*"), false},
+		{"prefix", Prefix(""), false},
+	}
+
+	for _, tt := range tests {
+		if got := tt.filter(newUpdate(t, increase)); got != tt.want {
+			t.Errorf("%s = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestCombinedFilters(t *testing.T) {
+	update := newUpdate(t, groupMessage("[CQ:image,file=a.png]", nil))
+
+	if !InGroups(100).And(HasImage(), Superuser()).Filter(update) {
+		t.Error("And() = false")
+	}
+	if InGroups(100).And(Role(hareru_cq.GroupAdminRole)).Filter(update) {
+		t.Error("And() with member role = true")
+	}
+	if !Private().Or(HasImage()).Filter(update) {
+		t.Error("Or() = false")
+	}
+	if Group().Not().Filter(update) {
+		t.Error("Not() = true")
+	}
+}
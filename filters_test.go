@@ -0,0 +1,78 @@
+package hareru_cq
+
+import "testing"
+
+func TestFilterCombinators(t *testing.T) {
+	update := newMessageUpdate(t, "hi")
+	pass := FilterFunc(func(*Update) bool { return true })
+	fail := FilterFunc(func(*Update) bool { return false })
+
+	tests := []struct {
+		name   string
+		filter Filter
+		want   bool
+	}{
+		{"empty and", And(), true},
+		{"empty or", Or(), false},
+		{"and", And(pass, pass), true},
+		{"and fail", And(pass, fail), false},
+		{"or", Or(fail, pass), true},
+		{"or fail", Or(fail, fail), false},
+		{"not", Not(fail), true},
+		{"not pass", Not(pass), false},
+		{"method and", pass.And(fail), false},
+		{"method or", fail.Or(pass), true},
+		{"method not", pass.Not(), false},
+		{"nested", pass.And(fail.Or(pass)).Not(), false},
+		{"event filter and", NewEventFilter(GroupMessageEvent).And(pass), true},
+		{"event filter or", NewEventFilter(PrivateMessageEvent).Or(fail), false},
+		{"event filter not", NewEventFilter(PrivateMessageEvent).Not(), true},
+	}
+
+	for _, tt := range tests {
+		if got := tt.filter.Filter(update); got != tt.want {
+			t.Errorf("%s = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestEventFilter(t *testing.T) {
+	groupMessage := newMessageUpdate(t, "hi")
+	privateMessage := newEventUpdate(t, map[string]any{"post_type": "message", "message_type": "private", "message": "hi"})
+	poke := newEventUpdate(t, map[string]any{"post_type": "notice", "notice_type": "notify", "sub_type": "poke"})
+	ban := newEventUpdate(t, map[string]any{"post_type": "notice", "notice_type": "group_ban", "sub_type": "ban"})
+	friendRequest := newEventUpdate(t, map[string]any{"post_type": "request", "request_type": "friend"})
+	heartbeat := newEventUpdate(t, map[string]any{"post_type": "meta_event", "meta_event_type": "heartbeat"})
+	disconnect := newEventUpdate(t, map[string]any{"post_type": "meta_event", "meta_event_type": ConnectionMetaEvent, "sub_type": DisconnectEvent})
+
+	tests := []struct {
+		name   string
+		types  []string
+		update *Update
+		want   bool
+	}{
+		{"no types", nil, poke, true},
+		{"message", []string{ReceiveMessageEvent}, privateMessage, true},
+		{"group message", []string{GroupMessageEvent}, groupMessage, true},
+		{"group message private", []string{GroupMessageEvent}, privateMessage, false},
+		{"any of", []string{GroupMessageEvent, PrivateMessageEvent}, privateMessage, true},
+		{"notify", []string{NotifyEvent}, poke, true},
+		{"notify sub type", []string{PokeEvent}, poke, true},
+		{"notify other sub type", []string{LuckyKingEvent}, poke, false},
+		{"notice", []string{GroupBanEvent}, ban, true},
+		{"notice other", []string{GroupBanEvent}, poke, false},
+		{"notice is not message", []string{ReceiveMessageEvent}, ban, false},
+		{"request", []string{FriendRequestEvent}, friendRequest, true},
+		{"request other", []string{GroupRequestEvent}, friendRequest, false},
+		{"meta event", []string{HeartbeatEvent}, heartbeat, true},
+		{"connection", []string{DisconnectEvent}, disconnect, true},
+		{"connection other sub type", []string{ConnectEvent}, disconnect, false},
+		{"unknown type", []string{"unknown"}, groupMessage, false},
+	}
+
+	for _, tt := range tests {
+		if got := NewEventFilter(tt.types...).Filter(tt.update); got != tt.want {
+			t.Errorf("%s = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,165 @@
+package hareru_cq
+
+import (
+	"context"
+	"strconv"
+)
+
+// ForwardBuilder 合并转发消息构建器
+//
+//	forward := NewForward().Node("日报", bot.Info().UserId, "第一段").Node("日报", bot.Info().UserId, chain)
+type ForwardBuilder struct {
+	nodes MessageChain
+	err   error
+}
+
+// NewForward 创建合并转发消息构建器
+func NewForward() *ForwardBuilder {
+	return &ForwardBuilder{
+		nodes: make(MessageChain, 0),
+	}
+}
+
+// Node 自定义消息节点
+// name string 发送者显示名
+// userId int64 发送者 QQ, 决定显示的头像
+// content any 消息内容, 可为 string (CQ 码), MessageChain, *MessageBuilder 或 Segment, 其他类型的错误由 Err 返回
+func (f *ForwardBuilder) Node(name string, userId int64, content any) *ForwardBuilder {
+	chain, err := toMessageChain(content, false)
+	if err != nil {
+		if f.err == nil {
+			f.err = err
+		}
+		return f
+	}
+
+	f.nodes = append(f.nodes, Segment{
+		Type: NodeSegment,
+		Data: map[string]any{
+			"name":    name,
+			"uin":     strconv.FormatInt(userId, 10),
+			"content": chain,
+		},
+	})
+	return f
+}
+
+// Ref 引用已有消息作为节点
+func (f *ForwardBuilder) Ref(messageId int64) *ForwardBuilder {
+	f.nodes = append(f.nodes, Segment{
+		Type: NodeSegment,
+		Data: map[string]any{"id": strconv.FormatInt(messageId, 10)},
+	})
+	return f
+}
+
+// Err 构建过程中的错误
+func (f *ForwardBuilder) Err() error {
+	return f.err
+}
+
+// Build 生成节点消息链
+func (f *ForwardBuilder) Build() MessageChain {
+	return f.nodes
+}
+
+// SendGroupForwardMessage 发送群合并转发消息 (go-cqhttp)
+func (bot *Bot) SendGroupForwardMessage(groupId int64, forward *ForwardBuilder) (*SentMessage, error) {
+	return bot.SendGroupForwardMessageCtx(context.Background(), groupId, forward)
+}
+
+// SendGroupForwardMessageCtx 发送群合并转发消息
+func (bot *Bot) SendGroupForwardMessageCtx(ctx context.Context, groupId int64, forward *ForwardBuilder) (*SentMessage, error) {
+	messages, err := bot.forwardMessages(forward)
+	if err != nil {
+		return nil, err
+	}
+
+	res, err := bot.call(ctx, "send_group_forward_msg", map[string]interface{}{
+		"group_id": groupId,
+		"messages": messages,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return bot.newSentMessage(GroupMessageType, groupId, res), nil
+}
+
+// SendPrivateForwardMessage 发送私聊合并转发消息 (go-cqhttp)
+func (bot *Bot) SendPrivateForwardMessage(userId int64, forward *ForwardBuilder) (*SentMessage, error) {
+	return bot.SendPrivateForwardMessageCtx(context.Background(), userId, forward)
+}
+
+// SendPrivateForwardMessageCtx 发送私聊合并转发消息
+func (bot *Bot) SendPrivateForwardMessageCtx(ctx context.Context, userId int64, forward *ForwardBuilder) (*SentMessage, error) {
+	messages, err := bot.forwardMessages(forward)
+	if err != nil {
+		return nil, err
+	}
+
+	res, err := bot.call(ctx, "send_private_forward_msg", map[string]interface{}{
+		"user_id":  userId,
+		"messages": messages,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return bot.newSentMessage(PrivateMessageType, userId, res), nil
+}
+
+// forwardMessages 合并转发的节点, 启用 EmbedLocalFiles 时转换其中的本地媒体文件
+func (bot *Bot) forwardMessages(forward *ForwardBuilder) (MessageChain, error) {
+	if forward.Err() != nil {
+		return nil, forward.Err()
+	}
+
+	if bot.EmbedLocalFiles {
+		return embedLocalFiles(forward.Build())
+	}
+	return forward.Build(), nil
+}
+
+// GetForwardMessage 获取合并转发消息的内容
+// id string [CQ:forward] 中的 id
+func (bot *Bot) GetForwardMessage(id string) ([]*Message, error) {
+	return bot.GetForwardMessageCtx(context.Background(), id)
+}
+
+// GetForwardMessageCtx 获取合并转发消息的内容
+func (bot *Bot) GetForwardMessageCtx(ctx context.Context, id string) ([]*Message, error) {
+	res, err := bot.call(ctx, "get_forward_msg", map[string]interface{}{
+		"message_id": id,
+		"id":         id,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	nodes := res.Json.Get("data.messages").Array()
+	messages := make([]*Message, 0, len(nodes))
+
+	for _, node := range nodes {
+		content := node.Get("content")
+		if !content.Exists() {
+			content = node.Get("message")
+		}
+		chain := ParseMessage(content)
+
+		messages = append(messages, &Message{
+			MessageID: node.Get("message_id").Int(),
+			Time:      node.Get("time").Int(),
+			Sender: &User{
+				UserId:   node.Get("sender.user_id").Int(),
+				NickName: node.Get("sender.nickname").String(),
+			},
+			RawMessage: chain.String(),
+			Chain:      chain,
+
+			Bot: bot,
+		})
+	}
+
+	return messages, nil
+}
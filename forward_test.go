@@ -0,0 +1,51 @@
+package hareru_cq
+
+import (
+	"errors"
+	"os"
+	"reflect"
+	"testing"
+)
+
+func TestForwardNode(t *testing.T) {
+	forward := NewForward().
+		Node("a", 1, "hi [CQ:face,id=1]").
+		Node("b", 2, NewMessage().Text("x")).
+		Node("c", 3, Segment{Type: FaceSegment, Data: map[string]any{"id": "2"}}).
+		Ref(99)
+	if forward.Err() != nil {
+		t.Fatal(forward.Err())
+	}
+
+	nodes := forward.Build()
+	if len(nodes) != 4 {
+		t.Fatalf("len = %d, want 4", len(nodes))
+	}
+	want := ParseCQString("hi [CQ:face,id=1]")
+	if got := nodes[0].Data["content"]; !reflect.DeepEqual(got, want) {
+		t.Errorf("string content = %#v, want %#v", got, want)
+	}
+	if nodes[0].Get("uin") != "1" || nodes[0].Get("name") != "a" {
+		t.Errorf("node = %+v", nodes[0])
+	}
+	if got := nodes[2].Data["content"].(MessageChain); len(got) != 1 || got[0].Type != FaceSegment {
+		t.Errorf("segment content = %#v", got)
+	}
+	if nodes[3].Get("id") != "99" {
+		t.Errorf("ref node = %+v", nodes[3])
+	}
+
+	var invalidErr *InvalidMessageErr
+	forward = NewForward().Node("a", 1, 42).Node("b", 2, "ok")
+	if !errors.As(forward.Err(), &invalidErr) {
+		t.Errorf("int content: Err() = %v, want InvalidMessageErr", forward.Err())
+	}
+	if len(forward.Build()) != 1 {
+		t.Errorf("invalid node added: %v", forward.Build())
+	}
+
+	forward = NewForward().Node("a", 1, NewMessage().ImageFrom("/nonexistent/a.png"))
+	if !errors.Is(forward.Err(), os.ErrNotExist) {
+		t.Errorf("builder error: Err() = %v, want not exist", forward.Err())
+	}
+}
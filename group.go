@@ -0,0 +1,154 @@
+package hareru_cq
+
+import (
+	"context"
+	"time"
+)
+
+// SetGroupKick 群组踢人
+// rejectAddRequest bool 是否拒绝此人的加群请求
+func (bot *Bot) SetGroupKick(groupId int64, userId int64, rejectAddRequest bool) error {
+	return bot.SetGroupKickCtx(context.Background(), groupId, userId, rejectAddRequest)
+}
+
+// SetGroupKickCtx 群组踢人
+func (bot *Bot) SetGroupKickCtx(ctx context.Context, groupId int64, userId int64, rejectAddRequest bool) error {
+	_, err := bot.call(ctx, "set_group_kick", map[string]interface{}{
+		"group_id":           groupId,
+		"user_id":            userId,
+		"reject_add_request": rejectAddRequest,
+	})
+	return err
+}
+
+// SetGroupBan 群组单人禁言
+// duration time.Duration 禁言时长, 精确到秒, 为 0 时解除禁言
+func (bot *Bot) SetGroupBan(groupId int64, userId int64, duration time.Duration) error {
+	return bot.SetGroupBanCtx(context.Background(), groupId, userId, duration)
+}
+
+// SetGroupBanCtx 群组单人禁言
+func (bot *Bot) SetGroupBanCtx(ctx context.Context, groupId int64, userId int64, duration time.Duration) error {
+	_, err := bot.call(ctx, "set_group_ban", map[string]interface{}{
+		"group_id": groupId,
+		"user_id":  userId,
+		"duration": int64(duration.Seconds()),
+	})
+	return err
+}
+
+// SetGroupAnonymousBan 群组匿名用户禁言
+// anonymousFlag string 匿名用户的 flag, 取自群消息事件的 anonymous.flag
+// duration time.Duration 禁言时长, 精确到秒, 无法取消
+func (bot *Bot) SetGroupAnonymousBan(groupId int64, anonymousFlag string, duration time.Duration) error {
+	return bot.SetGroupAnonymousBanCtx(context.Background(), groupId, anonymousFlag, duration)
+}
+
+// SetGroupAnonymousBanCtx 群组匿名用户禁言
+func (bot *Bot) SetGroupAnonymousBanCtx(ctx context.Context, groupId int64, anonymousFlag string, duration time.Duration) error {
+	_, err := bot.call(ctx, "set_group_anonymous_ban", map[string]interface{}{
+		"group_id":       groupId,
+		"anonymous_flag": anonymousFlag,
+		"duration":       int64(duration.Seconds()),
+	})
+	return err
+}
+
+// SetGroupWholeBan 群组全员禁言
+// enable bool 是否禁言
+func (bot *Bot) SetGroupWholeBan(groupId int64, enable bool) error {
+	return bot.SetGroupWholeBanCtx(context.Background(), groupId, enable)
+}
+
+// SetGroupWholeBanCtx 群组全员禁言
+func (bot *Bot) SetGroupWholeBanCtx(ctx context.Context, groupId int64, enable bool) error {
+	_, err := bot.call(ctx, "set_group_whole_ban", map[string]interface{}{
+		"group_id": groupId,
+		"enable":   enable,
+	})
+	return err
+}
+
+// SetGroupAdmin 群组设置管理员
+// enable bool true 为设置, false 为取消
+func (bot *Bot) SetGroupAdmin(groupId int64, userId int64, enable bool) error {
+	return bot.SetGroupAdminCtx(context.Background(), groupId, userId, enable)
+}
+
+// SetGroupAdminCtx 群组设置管理员
+func (bot *Bot) SetGroupAdminCtx(ctx context.Context, groupId int64, userId int64, enable bool) error {
+	_, err := bot.call(ctx, "set_group_admin", map[string]interface{}{
+		"group_id": groupId,
+		"user_id":  userId,
+		"enable":   enable,
+	})
+	return err
+}
+
+// SetGroupCard 设置群名片 (群备注)
+// card string 群名片, 为空时删除群名片
+func (bot *Bot) SetGroupCard(groupId int64, userId int64, card string) error {
+	return bot.SetGroupCardCtx(context.Background(), groupId, userId, card)
+}
+
+// SetGroupCardCtx 设置群名片 (群备注)
+func (bot *Bot) SetGroupCardCtx(ctx context.Context, groupId int64, userId int64, card string) error {
+	_, err := bot.call(ctx, "set_group_card", map[string]interface{}{
+		"group_id": groupId,
+		"user_id":  userId,
+		"card":     card,
+	})
+	return err
+}
+
+// SetGroupName 设置群名
+func (bot *Bot) SetGroupName(groupId int64, groupName string) error {
+	return bot.SetGroupNameCtx(context.Background(), groupId, groupName)
+}
+
+// SetGroupNameCtx 设置群名
+func (bot *Bot) SetGroupNameCtx(ctx context.Context, groupId int64, groupName string) error {
+	_, err := bot.call(ctx, "set_group_name", map[string]interface{}{
+		"group_id":   groupId,
+		"group_name": groupName,
+	})
+	return err
+}
+
+// SetGroupLeave 退出群组
+// isDismiss bool 是否解散, 仅在 Bot 是群主时有效
+func (bot *Bot) SetGroupLeave(groupId int64, isDismiss bool) error {
+	return bot.SetGroupLeaveCtx(context.Background(), groupId, isDismiss)
+}
+
+// SetGroupLeaveCtx 退出群组
+func (bot *Bot) SetGroupLeaveCtx(ctx context.Context, groupId int64, isDismiss bool) error {
+	_, err := bot.call(ctx, "set_group_leave", map[string]interface{}{
+		"group_id":   groupId,
+		"is_dismiss": isDismiss,
+	})
+	return err
+}
+
+// SetGroupSpecialTitle 设置群组专属头衔, 需要 Bot 是群主
+// title string 专属头衔, 为空时删除头衔
+// duration time.Duration 有效期, 精确到秒, 小于等于 0 时永久有效
+func (bot *Bot) SetGroupSpecialTitle(groupId int64, userId int64, title string, duration time.Duration) error {
+	return bot.SetGroupSpecialTitleCtx(context.Background(), groupId, userId, title, duration)
+}
+
+// SetGroupSpecialTitleCtx 设置群组专属头衔
+func (bot *Bot) SetGroupSpecialTitleCtx(ctx context.Context, groupId int64, userId int64, title string, duration time.Duration) error {
+	seconds := int64(duration.Seconds())
+	if seconds <= 0 {
+		seconds = -1
+	}
+
+	_, err := bot.call(ctx, "set_group_special_title", map[string]interface{}{
+		"group_id":      groupId,
+		"user_id":       userId,
+		"special_title": title,
+		"duration":      seconds,
+	})
+	return err
+}
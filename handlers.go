@@ -2,7 +2,6 @@ package hareru_cq
 
 import (
 	"regexp"
-	"strings"
 )
 
 // Handler Handler 接口
@@ -14,11 +13,14 @@ type Handler interface {
 
 // MessageHandler 消息处理器
 type MessageHandler struct {
-	Filter   Filter                      //消息过滤器
+	Filter   Filter                      //消息过滤器, 为 nil 时处理所有消息
 	Callback func(*Update, *Message) any //消息处理函数
 }
 
 func (h *MessageHandler) CheckUpdate(update *Update) bool {
+	if h.Filter == nil {
+		return update.Event.Type == "message"
+	}
 	return h.Filter.Filter(update)
 }
 
@@ -31,68 +33,89 @@ func (h *MessageHandler) CollectArgs(update *Update) {
 	return
 }
 
-// TextHandler 消息文本处理器
-type TextHandler struct {
-	MessagePattern string                      //消息匹配 正则表达式
-	Callback       func(*Update, *Message) any //消息处理函数
+// RequestHandler 请求处理器
+type RequestHandler struct {
+	Filter   Filter                     //请求过滤器, 为 nil 时处理所有请求
+	Callback func(*Update, Request) any //请求处理函数, Request 为 *FriendRequest 或 *GroupRequest
 }
 
-func (h *TextHandler) CheckUpdate(update *Update) bool {
-	filter := NewEventFilter()
-	if filter.Filter(update, ReceiveMessageEvent) {
-		re := regexp.MustCompile(h.MessagePattern)
-		return re.MatchString(update.Event.Json.Get("message").String())
+func (h *RequestHandler) CheckUpdate(update *Update) bool {
+	if update.Event.Type != "request" {
+		return false
 	}
-	return false
+	return h.Filter == nil || h.Filter.Filter(update)
 }
 
-func (h *TextHandler) HandleUpdate(update *Update) interface{} {
-	message := buildMessageByUpdate(update)
-	return h.Callback(update, message)
+func (h *RequestHandler) HandleUpdate(update *Update) interface{} {
+	return h.Callback(update, update.Request())
 }
 
-// CollectArgs prepare args
-func (h *TextHandler) CollectArgs(update *Update) {
+func (h *RequestHandler) CollectArgs(update *Update) {
 	return
 }
 
-func NewTextHandler(pattern string, callback func(*Update, *Message) any) TextHandler {
-	return TextHandler{
-		MessagePattern: pattern,
-		Callback:       callback,
+func NewRequestHandler(callback func(*Update, Request) any) RequestHandler {
+	return RequestHandler{
+		Callback: callback,
 	}
 }
 
-// CommandHandler 消息命令处理器
-type CommandHandler struct {
-	Command  string                      //命令 (写在!后面的部分)
-	Callback func(*Update, *Message) any //消息处理函数
+// NoticeHandler 通知处理器, 仅处理类型为 N 的通知, 如 NoticeHandler[*GroupBanNotice]
+type NoticeHandler[N Notice] struct {
+	Filter   Filter               //通知过滤器, 为 nil 时处理所有类型为 N 的通知
+	Callback func(*Update, N) any //通知处理函数
+}
+
+func (h *NoticeHandler[N]) CheckUpdate(update *Update) bool {
+	if _, ok := NoticeOf[N](update); !ok {
+		return false
+	}
+	return h.Filter == nil || h.Filter.Filter(update)
+}
 
-	args []string //消息参数
+func (h *NoticeHandler[N]) HandleUpdate(update *Update) interface{} {
+	notice, _ := NoticeOf[N](update)
+	return h.Callback(update, notice)
 }
 
-func (h *CommandHandler) CheckUpdate(update *Update) bool {
-	filter := NewEventFilter()
-	if filter.Filter(update, ReceiveMessageEvent) {
-		return strings.HasPrefix(update.Event.Json.Get("message").String(), "!"+h.Command)
+func (h *NoticeHandler[N]) CollectArgs(update *Update) {
+	return
+}
+
+func NewNoticeHandler[N Notice](callback func(*Update, N) any) NoticeHandler[N] {
+	return NoticeHandler[N]{
+		Callback: callback,
+	}
+}
+
+// TextHandler 消息文本处理器
+type TextHandler struct {
+	MessagePattern string                      //消息匹配 正则表达式
+	Callback       func(*Update, *Message) any //消息处理函数
+}
+
+func (h *TextHandler) CheckUpdate(update *Update) bool {
+	filter := NewEventFilter(ReceiveMessageEvent)
+	if filter.Filter(update) {
+		re := regexp.MustCompile(h.MessagePattern)
+		return re.MatchString(update.Event.MessageString())
 	}
 	return false
 }
 
-func (h *CommandHandler) HandleUpdate(update *Update) interface{} {
+func (h *TextHandler) HandleUpdate(update *Update) interface{} {
 	message := buildMessageByUpdate(update)
 	return h.Callback(update, message)
 }
 
 // CollectArgs prepare args
-func (h *CommandHandler) CollectArgs(update *Update) {
-	message := strings.TrimPrefix(update.Event.Get("message").String(), "!")
-	h.args = strings.Split(message, " ")
+func (h *TextHandler) CollectArgs(update *Update) {
+	return
 }
 
-func NewCommandHandler(command string, callback func(*Update, *Message) any) CommandHandler {
-	return CommandHandler{
-		Command:  command,
-		Callback: callback,
+func NewTextHandler(pattern string, callback func(*Update, *Message) any) TextHandler {
+	return TextHandler{
+		MessagePattern: pattern,
+		Callback:       callback,
 	}
 }
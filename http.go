@@ -0,0 +1,160 @@
+package hareru_cq
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha1"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/tidwall/gjson"
+)
+
+const (
+	quickOperationTimeout = 5 * time.Second // HTTP POST 上报等待快速操作的时长
+	maxPostBodySize       = 10 << 20        // HTTP POST 上报数据大小上限
+)
+
+// postAction 通过 HTTP API 调用 Action
+func (c *Client) postAction(ctx context.Context, req *CqRequest) (*CqResponse, error) {
+	params := req.Params
+	if params == nil {
+		params = map[string]any{}
+	}
+
+	body, err := json.Marshal(params)
+	if err != nil {
+		return nil, err
+	}
+
+	url := strings.TrimSuffix(c.HttpUrl, "/") + "/" + req.Action
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	httpReq.Header = c.authHeader()
+	httpReq.Header.Set("Content-Type", "application/json")
+
+	response, err := c.httpClient.Do(httpReq)
+	if err != nil {
+		return nil, err
+	}
+	defer response.Body.Close()
+
+	data, err := io.ReadAll(response.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	err = checkAuthResponse(response)
+	if err != nil {
+		return nil, err
+	}
+
+	if response.StatusCode != http.StatusOK {
+		return nil, &ActionFailErr{
+			Action:  req.Action,
+			Message: fmt.Sprintf("http %d", response.StatusCode),
+		}
+	}
+
+	res := &CqResponse{}
+	err = json.Unmarshal(data, res)
+	if err != nil {
+		return nil, err
+	}
+
+	res.Echo = req.Echo
+	res.Json = gjson.ParseBytes(data)
+
+	return res, nil
+}
+
+// servePost 接收 HTTP POST 上报的事件, 并以响应体回传快速操作
+func (c *Client) servePost(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+
+	// cqhttp 的 HTTP POST 上报以 X-Signature 签名, 通常不携带 AccessToken
+	// 未设置 Secret 时无签名可校验, 必须携带 AccessToken (post.url 中的 access_token 参数)
+	if !c.authorize(w, r, c.Secret == "") {
+		return
+	}
+
+	body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxPostBodySize))
+	if err != nil {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			log.Warnln("上报数据过大, 已拒绝来自", r.RemoteAddr, "的事件")
+			w.WriteHeader(http.StatusRequestEntityTooLarge)
+			return
+		}
+
+		log.Error("读取上报数据失败:", err)
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	if c.Secret != "" && !verifySignature(c.Secret, r.Header.Get("X-Signature"), body) {
+		log.Warnln("上报签名校验失败, 已拒绝来自", r.RemoteAddr, "的事件")
+		w.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+
+	frame := &eventFrame{
+		data:  body,
+		reply: make(chan *QuickOperation, 1),
+	}
+
+	timeout := time.NewTimer(quickOperationTimeout)
+	defer timeout.Stop()
+
+	select {
+	case c.events <- frame:
+	case <-timeout.C:
+		log.Warnln("事件处理繁忙, 已丢弃上报")
+		w.WriteHeader(http.StatusServiceUnavailable)
+		return
+	}
+
+	select {
+	case op := <-frame.reply:
+		if op == nil {
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(op)
+	case <-timeout.C:
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
+
+// verifySignature 校验 X-Signature (sha1=HMAC-SHA1(secret, body))
+func verifySignature(secret string, signature string, body []byte) bool {
+	hexSum, found := strings.CutPrefix(signature, "sha1=")
+	if !found {
+		return false
+	}
+
+	sum, err := hex.DecodeString(hexSum)
+	if err != nil {
+		return false
+	}
+
+	mac := hmac.New(sha1.New, []byte(secret))
+	mac.Write(body)
+
+	return hmac.Equal(sum, mac.Sum(nil))
+}
@@ -0,0 +1,147 @@
+package hareru_cq
+
+import (
+	"crypto/hmac"
+	"crypto/sha1"
+	"encoding/hex"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func sign(secret string, body string) string {
+	mac := hmac.New(sha1.New, []byte(secret))
+	mac.Write([]byte(body))
+	return "sha1=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+func TestVerifySignature(t *testing.T) {
+	body := []byte(`{"post_type":"meta_event"}`)
+	tests := []struct {
+		name      string
+		signature string
+		want      bool
+	}{
+		{"valid", sign("secret", string(body)), true},
+		{"wrong secret", sign("other", string(body)), false},
+		{"missing prefix", strings.TrimPrefix(sign("secret", string(body)), "sha1="), false},
+		{"not hex", "sha1=zz", false},
+		{"empty", "", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := verifySignature("secret", tt.signature, body); got != tt.want {
+				t.Errorf("verifySignature() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestServePostSignature(t *testing.T) {
+	body := `{"time":1,"self_id":10,"post_type":"message","message_type":"private","user_id":6,"message":"hi"}`
+	tests := []struct {
+		name      string
+		signature string
+		want      int
+	}{
+		{"valid", sign("secret", body), http.StatusOK},
+		{"bad", sign("other", body), http.StatusUnauthorized},
+		{"missing", "", http.StatusUnauthorized},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := NewHttpClient("http://127.0.0.1:5700", "127.0.0.1:0", "")
+			client.Secret = "secret"
+			client.events = make(chan *eventFrame, 1)
+
+			if tt.want == http.StatusOK {
+				go func() {
+					frame := <-client.events
+					frame.reply <- QuickReply("ok")
+				}()
+			}
+
+			req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(body))
+			if tt.signature != "" {
+				req.Header.Set("X-Signature", tt.signature)
+			}
+			recorder := httptest.NewRecorder()
+			client.servePost(recorder, req)
+
+			if recorder.Code != tt.want {
+				t.Fatalf("status = %d, want %d", recorder.Code, tt.want)
+			}
+			if tt.want == http.StatusOK && !strings.Contains(recorder.Body.String(), `"reply":"ok"`) {
+				t.Errorf("body = %s, want quick reply", recorder.Body.String())
+			}
+		})
+	}
+}
+
+func TestServePostAccessToken(t *testing.T) {
+	body := `{"time":1,"self_id":10,"post_type":"meta_event","meta_event_type":"heartbeat"}`
+	tests := []struct {
+		name   string
+		secret string
+		target string
+		header string
+		want   int
+	}{
+		{"missing", "", "/", "", http.StatusUnauthorized},
+		{"mismatch", "", "/", "Bearer other", http.StatusForbidden},
+		{"bearer", "", "/", "Bearer token", http.StatusNoContent},
+		{"query", "", "/?access_token=token", "", http.StatusNoContent},
+		{"signed without token", "secret", "/", "", http.StatusNoContent},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := NewHttpClient("http://127.0.0.1:5700", "127.0.0.1:0", "token")
+			client.Secret = tt.secret
+			client.events = make(chan *eventFrame, 1)
+
+			if tt.want == http.StatusNoContent {
+				go func() {
+					frame := <-client.events
+					frame.reply <- nil
+				}()
+			}
+
+			req := httptest.NewRequest(http.MethodPost, tt.target, strings.NewReader(body))
+			if tt.header != "" {
+				req.Header.Set("Authorization", tt.header)
+			}
+			if tt.secret != "" {
+				req.Header.Set("X-Signature", sign(tt.secret, body))
+			}
+			recorder := httptest.NewRecorder()
+			client.servePost(recorder, req)
+
+			if recorder.Code != tt.want {
+				t.Errorf("status = %d, want %d", recorder.Code, tt.want)
+			}
+		})
+	}
+}
+
+func TestServePostBodyTooLarge(t *testing.T) {
+	client := NewHttpClient("http://127.0.0.1:5700", "127.0.0.1:0", "")
+	client.Secret = "secret"
+	client.events = make(chan *eventFrame, 1)
+
+	body := strings.Repeat("a", maxPostBodySize+1)
+	req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(body))
+	req.Header.Set("X-Signature", sign("secret", body))
+	recorder := httptest.NewRecorder()
+	client.servePost(recorder, req)
+
+	if recorder.Code != http.StatusRequestEntityTooLarge {
+		t.Errorf("status = %d, want %d", recorder.Code, http.StatusRequestEntityTooLarge)
+	}
+	if len(client.events) != 0 {
+		t.Error("oversized body queued as event")
+	}
+}
@@ -0,0 +1,158 @@
+package hareru_cq
+
+import "context"
+
+const (
+	TalkativeHonor    = "talkative" // 群荣誉类型
+	PerformerHonor    = "performer"
+	LegendHonor       = "legend"
+	StrongNewbieHonor = "strong_newbie"
+	EmotionHonor      = "emotion"
+	AllHonor          = "all"
+)
+
+// GetStrangerInfo 获取陌生人信息
+// noCache bool 是否不使用缓存
+func (bot *Bot) GetStrangerInfo(userId int64, noCache bool) (*StrangerInfo, error) {
+	return bot.GetStrangerInfoCtx(context.Background(), userId, noCache)
+}
+
+// GetStrangerInfoCtx 获取陌生人信息
+func (bot *Bot) GetStrangerInfoCtx(ctx context.Context, userId int64, noCache bool) (*StrangerInfo, error) {
+	info, err := CallAction[StrangerInfo](ctx, bot, "get_stranger_info", map[string]interface{}{
+		"user_id":  userId,
+		"no_cache": noCache,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &info, nil
+}
+
+// GetFriendList 获取好友列表
+func (bot *Bot) GetFriendList() ([]Friend, error) {
+	return bot.GetFriendListCtx(context.Background())
+}
+
+// GetFriendListCtx 获取好友列表
+func (bot *Bot) GetFriendListCtx(ctx context.Context) ([]Friend, error) {
+	return CallAction[[]Friend](ctx, bot, "get_friend_list", nil)
+}
+
+// GetGroupInfo 获取群信息
+// noCache bool 是否不使用缓存
+func (bot *Bot) GetGroupInfo(groupId int64, noCache bool) (*GroupInfo, error) {
+	return bot.GetGroupInfoCtx(context.Background(), groupId, noCache)
+}
+
+// GetGroupInfoCtx 获取群信息
+func (bot *Bot) GetGroupInfoCtx(ctx context.Context, groupId int64, noCache bool) (*GroupInfo, error) {
+	info, err := CallAction[GroupInfo](ctx, bot, "get_group_info", map[string]interface{}{
+		"group_id": groupId,
+		"no_cache": noCache,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &info, nil
+}
+
+// GetGroupMemberList 获取群成员列表
+func (bot *Bot) GetGroupMemberList(groupId int64) ([]*GroupMember, error) {
+	return bot.GetGroupMemberListCtx(context.Background(), groupId)
+}
+
+// GetGroupMemberListCtx 获取群成员列表
+func (bot *Bot) GetGroupMemberListCtx(ctx context.Context, groupId int64) ([]*GroupMember, error) {
+	members, err := CallAction[[]*GroupMember](ctx, bot, "get_group_member_list", map[string]interface{}{
+		"group_id": groupId,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	for _, member := range members {
+		member.GroupId = groupId
+		member.Bot = bot
+	}
+
+	return members, nil
+}
+
+// GetGroupHonorInfo 获取群荣誉信息
+// honorType string 荣誉类型, 如 TalkativeHonor, AllHonor
+func (bot *Bot) GetGroupHonorInfo(groupId int64, honorType string) (*GroupHonorInfo, error) {
+	return bot.GetGroupHonorInfoCtx(context.Background(), groupId, honorType)
+}
+
+// GetGroupHonorInfoCtx 获取群荣誉信息
+func (bot *Bot) GetGroupHonorInfoCtx(ctx context.Context, groupId int64, honorType string) (*GroupHonorInfo, error) {
+	info, err := CallAction[GroupHonorInfo](ctx, bot, "get_group_honor_info", map[string]interface{}{
+		"group_id": groupId,
+		"type":     honorType,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &info, nil
+}
+
+// GetStatus 获取运行状态
+func (bot *Bot) GetStatus() (*BotStatus, error) {
+	return bot.GetStatusCtx(context.Background())
+}
+
+// GetStatusCtx 获取运行状态
+func (bot *Bot) GetStatusCtx(ctx context.Context) (*BotStatus, error) {
+	status, err := CallAction[BotStatus](ctx, bot, "get_status", nil)
+	if err != nil {
+		return nil, err
+	}
+
+	return &status, nil
+}
+
+// GetVersionInfo 获取版本信息
+func (bot *Bot) GetVersionInfo() (*VersionInfo, error) {
+	return bot.GetVersionInfoCtx(context.Background())
+}
+
+// GetVersionInfoCtx 获取版本信息
+func (bot *Bot) GetVersionInfoCtx(ctx context.Context) (*VersionInfo, error) {
+	info, err := CallAction[VersionInfo](ctx, bot, "get_version_info", nil)
+	if err != nil {
+		return nil, err
+	}
+
+	return &info, nil
+}
+
+// CanSendImage 检查是否可以发送图片
+func (bot *Bot) CanSendImage() (bool, error) {
+	return bot.CanSendImageCtx(context.Background())
+}
+
+// CanSendImageCtx 检查是否可以发送图片
+func (bot *Bot) CanSendImageCtx(ctx context.Context) (bool, error) {
+	return bot.canSend(ctx, "can_send_image")
+}
+
+// CanSendRecord 检查是否可以发送语音
+func (bot *Bot) CanSendRecord() (bool, error) {
+	return bot.CanSendRecordCtx(context.Background())
+}
+
+// CanSendRecordCtx 检查是否可以发送语音
+func (bot *Bot) CanSendRecordCtx(ctx context.Context) (bool, error) {
+	return bot.canSend(ctx, "can_send_record")
+}
+
+func (bot *Bot) canSend(ctx context.Context, action string) (bool, error) {
+	res, err := CallAction[struct {
+		Yes bool `json:"yes"`
+	}](ctx, bot, action, nil)
+	return res.Yes, err
+}
@@ -0,0 +1,276 @@
+package hareru_cq
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"fmt"
+	"image"
+	"image/png"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+const (
+	MaxImageSize  = 30 << 20  // 图片大小上限
+	MaxRecordSize = 20 << 20  // 语音大小上限
+	MaxVideoSize  = 100 << 20 // 短视频大小上限
+)
+
+// mediaLimits 各媒体类型的大小上限
+var mediaLimits = map[string]int64{
+	ImageSegment:  MaxImageSize,
+	RecordSegment: MaxRecordSize,
+	VideoSegment:  MaxVideoSize,
+}
+
+// MediaFile 将媒体源转换为消息段的 file 参数
+// source any 媒体源:
+//   - string 本地路径, 转换为 file:/// URI (需 cqhttp 与 Bot 在同一主机, 否则启用 Bot.EmbedLocalFiles); 已是 http(s)://, file://, base64:// 的 URI 原样使用
+//   - []byte, io.Reader 转换为 base64:// URI
+//   - image.Image 编码为 PNG 后转换为 base64:// URI
+//
+// segType string ImageSegment, RecordSegment 或 VideoSegment, 用于大小限制与内容类型检查, 其他类型返回 InvalidMessageErr
+func MediaFile(source any, segType string) (string, error) {
+	limit, ok := mediaLimits[segType]
+	if !ok {
+		return "", &InvalidMessageErr{"unsupported media segment " + segType}
+	}
+
+	switch media := source.(type) {
+	case string:
+		if isMediaUri(media) {
+			return media, nil
+		}
+		return mediaPath(media, segType)
+	case []byte:
+		return mediaBase64(media, segType)
+	case image.Image:
+		buffer := &bytes.Buffer{}
+		err := png.Encode(buffer, media)
+		if err != nil {
+			return "", err
+		}
+		return mediaBase64(buffer.Bytes(), segType)
+	case io.Reader:
+		data, err := io.ReadAll(io.LimitReader(media, limit+1))
+		if err != nil {
+			return "", err
+		}
+		if int64(len(data)) > limit {
+			return "", &MediaTooLargeErr{Limit: limit}
+		}
+		return mediaBase64(data, segType)
+	}
+
+	return "", &InvalidMessageErr{fmt.Sprintf("unsupported media source %T", source)}
+}
+
+func isMediaUri(file string) bool {
+	for _, scheme := range []string{"http://", "https://", "file://", "base64://"} {
+		if strings.HasPrefix(file, scheme) {
+			return true
+		}
+	}
+	return false
+}
+
+// mediaPath 检查本地文件并转换为 file:/// URI
+func mediaPath(path string, segType string) (string, error) {
+	absPath, err := filepath.Abs(path)
+	if err != nil {
+		return "", err
+	}
+
+	file, err := os.Open(absPath)
+	if err != nil {
+		return "", err
+	}
+	defer file.Close()
+
+	stat, err := file.Stat()
+	if err != nil {
+		return "", err
+	}
+
+	err = checkMediaSize(stat.Size(), segType)
+	if err != nil {
+		return "", err
+	}
+
+	head := make([]byte, 512)
+	n, err := io.ReadFull(file, head)
+	if err != nil && err != io.ErrUnexpectedEOF && err != io.EOF {
+		return "", err
+	}
+
+	err = checkMediaType(head[:n], segType)
+	if err != nil {
+		return "", err
+	}
+
+	slashPath := filepath.ToSlash(absPath)
+	if !strings.HasPrefix(slashPath, "/") {
+		slashPath = "/" + slashPath
+	}
+
+	return (&url.URL{Scheme: "file", Path: slashPath}).String(), nil
+}
+
+// mediaBase64 检查数据并转换为 base64:// URI
+func mediaBase64(data []byte, segType string) (string, error) {
+	err := checkMediaSize(int64(len(data)), segType)
+	if err != nil {
+		return "", err
+	}
+
+	err = checkMediaType(data, segType)
+	if err != nil {
+		return "", err
+	}
+
+	return "base64://" + base64.StdEncoding.EncodeToString(data), nil
+}
+
+// embedLocalFiles 将消息链中 file:/// 媒体文件读取为 base64:// URI, 合并转发节点的内容一并转换
+// 返回新的消息链, 原消息段不被修改
+func embedLocalFiles(chain MessageChain) (MessageChain, error) {
+	embedded := make(MessageChain, len(chain))
+	for i, seg := range chain {
+		_, isMedia := mediaLimits[seg.Type]
+
+		switch {
+		case isMedia && strings.HasPrefix(seg.Get("file"), "file://"):
+			file, err := embedFile(seg.Get("file"), seg.Type)
+			if err != nil {
+				return nil, err
+			}
+			seg = seg.with("file", file)
+		case seg.Type == NodeSegment && seg.Data["content"] != nil:
+			content, err := toMessageChain(seg.Data["content"], false)
+			if err != nil {
+				return nil, err
+			}
+			content, err = embedLocalFiles(content)
+			if err != nil {
+				return nil, err
+			}
+			seg = seg.with("content", content)
+		}
+
+		embedded[i] = seg
+	}
+	return embedded, nil
+}
+
+// embedFile 读取 file:/// URI 指向的文件并转换为 base64:// URI
+func embedFile(uri string, segType string) (string, error) {
+	fileUrl, err := url.Parse(uri)
+	if err != nil {
+		return "", err
+	}
+
+	path := filepath.FromSlash(fileUrl.Path)
+	if len(path) > 1 && filepath.VolumeName(path[1:]) != "" {
+		path = path[1:] // Windows: /C:/a.png
+	}
+
+	stat, err := os.Stat(path)
+	if err != nil {
+		return "", err
+	}
+
+	err = checkMediaSize(stat.Size(), segType)
+	if err != nil {
+		return "", err
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", err
+	}
+	return mediaBase64(data, segType)
+}
+
+func checkMediaSize(size int64, segType string) error {
+	limit, ok := mediaLimits[segType]
+	if ok && size > limit {
+		return &MediaTooLargeErr{Size: size, Limit: limit}
+	}
+	return nil
+}
+
+// checkMediaType 按文件头检查内容类型
+// 语音 (silk, amr) 与部分视频格式无法识别, 此时不做限制
+func checkMediaType(head []byte, segType string) error {
+	contentType := http.DetectContentType(head)
+	if contentType == "application/octet-stream" && segType != ImageSegment {
+		return nil
+	}
+
+	var ok bool
+	switch segType {
+	case ImageSegment:
+		ok = strings.HasPrefix(contentType, "image/")
+	case RecordSegment:
+		ok = strings.HasPrefix(contentType, "audio/") || contentType == "application/ogg"
+	case VideoSegment:
+		ok = strings.HasPrefix(contentType, "video/")
+	default:
+		ok = true
+	}
+
+	if !ok {
+		return &InvalidMessageErr{fmt.Sprintf("%s is not a valid %s", contentType, segType)}
+	}
+	return nil
+}
+
+// SendMessage 发送消息
+// messageType string GroupMessageType 或 PrivateMessageType
+// targetId int64 群组 ID 或用户 ID
+func (bot *Bot) SendMessage(messageType string, targetId int64, message any) (*SentMessage, error) {
+	return bot.SendMessageCtx(context.Background(), messageType, targetId, message)
+}
+
+// SendMessageCtx 发送消息
+func (bot *Bot) SendMessageCtx(ctx context.Context, messageType string, targetId int64, message any) (*SentMessage, error) {
+	return bot.sendMessage(ctx, messageType, targetId, message, false)
+}
+
+// SendImage 发送图片
+// source any 媒体源, 见 MediaFile
+func (bot *Bot) SendImage(messageType string, targetId int64, source any) (*SentMessage, error) {
+	return bot.SendImageCtx(context.Background(), messageType, targetId, source)
+}
+
+// SendImageCtx 发送图片
+func (bot *Bot) SendImageCtx(ctx context.Context, messageType string, targetId int64, source any) (*SentMessage, error) {
+	return bot.SendMessageCtx(ctx, messageType, targetId, NewMessage().ImageFrom(source))
+}
+
+// SendRecord 发送语音
+// source any 媒体源, 见 MediaFile
+func (bot *Bot) SendRecord(messageType string, targetId int64, source any) (*SentMessage, error) {
+	return bot.SendRecordCtx(context.Background(), messageType, targetId, source)
+}
+
+// SendRecordCtx 发送语音
+func (bot *Bot) SendRecordCtx(ctx context.Context, messageType string, targetId int64, source any) (*SentMessage, error) {
+	return bot.SendMessageCtx(ctx, messageType, targetId, NewMessage().RecordFrom(source))
+}
+
+// SendVideo 发送短视频
+// source any 媒体源, 见 MediaFile
+func (bot *Bot) SendVideo(messageType string, targetId int64, source any) (*SentMessage, error) {
+	return bot.SendVideoCtx(context.Background(), messageType, targetId, source)
+}
+
+// SendVideoCtx 发送短视频
+func (bot *Bot) SendVideoCtx(ctx context.Context, messageType string, targetId int64, source any) (*SentMessage, error) {
+	return bot.SendMessageCtx(ctx, messageType, targetId, NewMessage().VideoFrom(source))
+}
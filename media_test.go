@@ -0,0 +1,88 @@
+package hareru_cq
+
+import (
+	"bytes"
+	"errors"
+	"image"
+	"image/png"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestMediaFileReader(t *testing.T) {
+	img := image.NewRGBA(image.Rect(0, 0, 1, 1))
+	file, err := MediaFile(img, ImageSegment)
+	if err != nil || !strings.HasPrefix(file, "base64://") {
+		t.Fatalf("MediaFile(image) = %q, %v", file, err)
+	}
+
+	data := bytes.Repeat([]byte{0}, 1024)
+	file, err = MediaFile(bytes.NewReader(data), RecordSegment)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := mustBase64(t, data, RecordSegment); file != want {
+		t.Errorf("reader truncated: got %d chars, want %d", len(file), len(want))
+	}
+
+	_, err = MediaFile(bytes.NewReader(make([]byte, MaxRecordSize+100)), RecordSegment)
+	var tooLargeErr *MediaTooLargeErr
+	if !errors.As(err, &tooLargeErr) || tooLargeErr.Size != 0 || tooLargeErr.Limit != MaxRecordSize {
+		t.Errorf("oversized reader: err = %#v, want MediaTooLargeErr with unknown size", err)
+	}
+
+	_, err = MediaFile(bytes.NewReader(data), "file")
+	var invalidErr *InvalidMessageErr
+	if !errors.As(err, &invalidErr) {
+		t.Errorf("unknown segment type: err = %v, want InvalidMessageErr", err)
+	}
+}
+
+func mustBase64(t *testing.T, data []byte, segType string) string {
+	file, err := mediaBase64(data, segType)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return file
+}
+
+func TestEmbedLocalFiles(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "a.png")
+	buffer := &bytes.Buffer{}
+	if err := png.Encode(buffer, image.NewRGBA(image.Rect(0, 0, 1, 1))); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, buffer.Bytes(), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	builder := NewMessage().Text("hi").ImageFrom(path)
+	if !strings.HasPrefix(builder.Build()[1].Get("file"), "file:///") {
+		t.Fatalf("local path not converted to file URI: %v", builder.Build())
+	}
+	forward := NewForward().Node("a", 1, builder).Node("b", 2, "[CQ:record,file=https://a.com/a.silk]")
+
+	chain, err := embedLocalFiles(forward.Build())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := mustBase64(t, buffer.Bytes(), ImageSegment)
+	content := chain[0].Data["content"].(MessageChain)
+	if got := content[1].Get("file"); got != want {
+		t.Errorf("node image = %.40q, want %.40q", got, want)
+	}
+	if got := chain[1].Data["content"].(MessageChain)[0].Get("file"); got != "https://a.com/a.silk" {
+		t.Errorf("remote file changed: %q", got)
+	}
+	if !strings.HasPrefix(builder.Build()[1].Get("file"), "file:///") {
+		t.Error("original segment modified")
+	}
+
+	_, err = embedLocalFiles(MessageChain{{Type: ImageSegment, Data: map[string]any{"file": "file:///nonexistent/a.png"}}})
+	if err == nil {
+		t.Error("expected error for missing file")
+	}
+}
@@ -1,32 +1,111 @@
 package hareru_cq
 
 import (
-	"fmt"
-	"regexp"
-	"strconv"
+	"context"
+	"encoding/json"
+	"time"
+
+	log "github.com/sirupsen/logrus"
 )
 
 type User struct {
-	UserId   int64
-	NickName string
+	UserId   int64  `json:"user_id"`
+	NickName string `json:"nickname"`
 }
 
 type GroupMember struct {
-	User            *User
-	Card            string
-	JoinTime        int64
-	LastSentTime    int64
-	Level           string
-	Role            string
-	CardChangeable  bool
-	ShutUpTimeStamp int64
+	User            *User  `json:"-"`
+	GroupId         int64  `json:"group_id"`
+	Card            string `json:"card"`
+	JoinTime        int64  `json:"join_time"`
+	LastSentTime    int64  `json:"last_sent_time"`
+	Level           string `json:"level"`
+	Role            string `json:"role"`
+	CardChangeable  bool   `json:"card_changeable"`
+	ShutUpTimeStamp int64  `json:"shut_up_timestamp"`
+	Sex             string `json:"sex"` // male, female, unknown
+	Age             int32  `json:"age"`
+	Area            string `json:"area"`
+	Title           string `json:"title"` // 专属头衔
+	TitleExpireTime int64  `json:"title_expire_time"`
+	Unfriendly      bool   `json:"unfriendly"` // 是否不良记录成员
+
+	Bot *Bot `json:"-"`
+}
+
+// StrangerInfo 陌生人信息
+type StrangerInfo struct {
+	User
+	Sex string `json:"sex"` // male, female, unknown
+	Age int32  `json:"age"`
+}
+
+// Friend 好友
+type Friend struct {
+	User
+	Remark string `json:"remark"` // 备注名
+}
+
+// GroupInfo 群信息
+type GroupInfo struct {
+	GroupId        int64  `json:"group_id"`
+	GroupName      string `json:"group_name"`
+	MemberCount    int32  `json:"member_count"`     // 成员数
+	MaxMemberCount int32  `json:"max_member_count"` // 最大成员数 (群容量)
+}
+
+// GroupHonorInfo 群荣誉信息, 未请求的类型为空
+type GroupHonorInfo struct {
+	GroupId          int64         `json:"group_id"`
+	CurrentTalkative *HonorMember  `json:"current_talkative"`  // 当前龙王
+	TalkativeList    []HonorMember `json:"talkative_list"`     // 历史龙王
+	PerformerList    []HonorMember `json:"performer_list"`     // 群聊之火
+	LegendList       []HonorMember `json:"legend_list"`        // 群聊炽焰
+	StrongNewbieList []HonorMember `json:"strong_newbie_list"` // 冒尖小春笋
+	EmotionList      []HonorMember `json:"emotion_list"`       // 快乐之源
+}
+
+// HonorMember 群荣誉成员
+type HonorMember struct {
+	User
+	Avatar      string `json:"avatar"`
+	DayCount    int32  `json:"day_count"`   // 持续天数, 仅 CurrentTalkative
+	Description string `json:"description"` // 荣誉描述
+}
+
+// BotStatus 运行状态
+type BotStatus struct {
+	Online bool `json:"online"` // 当前 QQ 在线, 无法查询时为 false
+	Good   bool `json:"good"`   // 状态符合预期
+}
+
+// VersionInfo 版本信息
+type VersionInfo struct {
+	AppName         string `json:"app_name"`
+	AppVersion      string `json:"app_version"`
+	ProtocolVersion string `json:"protocol_version"`
+}
+
+// UnmarshalJSON 解析 cqhttp 的群成员信息, user_id 与 nickname 填入 User
+func (member *GroupMember) UnmarshalJSON(data []byte) error {
+	type groupMember GroupMember
+
+	err := json.Unmarshal(data, (*groupMember)(member))
+	if err != nil {
+		return err
+	}
+
+	member.User = &User{}
+	return json.Unmarshal(data, member.User)
 }
 
 type Message struct {
 	MessageType string `json:"message_type"`
 	MessageID   int64  `json:"message_id"`
+	Time        int64  `json:"time"`
 	Sender      *User
-	RawMessage  string `json:"raw_message"`
+	RawMessage  string       `json:"raw_message"`
+	Chain       MessageChain // 解析后的消息段
 
 	// if message_type is group, this field is available
 	GroupId     int64 `json:"group_id"`
@@ -34,6 +113,36 @@ type Message struct {
 	Bot         *Bot
 }
 
+// Ban 禁言该成员, duration 为 0 时解除禁言
+func (member *GroupMember) Ban(duration time.Duration) error {
+	return member.Bot.SetGroupBan(member.GroupId, member.User.UserId, duration)
+}
+
+// Unban 解除禁言
+func (member *GroupMember) Unban() error {
+	return member.Bot.SetGroupBan(member.GroupId, member.User.UserId, 0)
+}
+
+// Kick 踢出该成员
+func (member *GroupMember) Kick(rejectAddRequest bool) error {
+	return member.Bot.SetGroupKick(member.GroupId, member.User.UserId, rejectAddRequest)
+}
+
+// SetCard 设置群名片
+func (member *GroupMember) SetCard(card string) error {
+	return member.Bot.SetGroupCard(member.GroupId, member.User.UserId, card)
+}
+
+// SetAdmin 设置或取消管理员
+func (member *GroupMember) SetAdmin(enable bool) error {
+	return member.Bot.SetGroupAdmin(member.GroupId, member.User.UserId, enable)
+}
+
+// SetSpecialTitle 设置专属头衔
+func (member *GroupMember) SetSpecialTitle(title string, duration time.Duration) error {
+	return member.Bot.SetGroupSpecialTitle(member.GroupId, member.User.UserId, title, duration)
+}
+
 func (msg *Message) IsGroupMessage() bool {
 	return msg.MessageType == "group"
 }
@@ -42,38 +151,100 @@ func (msg *Message) IsPrivateMessage() bool {
 	return msg.MessageType == "private"
 }
 
-func (msg *Message) ReplyMessage(message string, explicit bool) error {
-	if explicit {
-		message = fmt.Sprintf("[CQ:reply,id=%d] %s", msg.MessageID, message)
-	} else {
-		message = fmt.Sprintf("[CQ:at,qq=%d] %s", msg.Sender.UserId, message)
+// ReplyOptions 回复选项
+type ReplyOptions struct {
+	Quote      bool // 引用原消息
+	Mention    bool // @发送者, 仅群聊有效
+	AutoEscape bool // message 为 string 时作为纯文本发送
+}
+
+// Reply 在原消息所在的群聊或私聊中回复
+// message any 消息内容, 可为 string (CQ 码), MessageChain, *MessageBuilder 或 Segment
+func (msg *Message) Reply(message any, opts ReplyOptions) (*SentMessage, error) {
+	return msg.ReplyCtx(context.Background(), message, opts)
+}
+
+// ReplyCtx 回复消息
+func (msg *Message) ReplyCtx(ctx context.Context, message any, opts ReplyOptions) (*SentMessage, error) {
+	builder := NewMessage()
+	if opts.Quote {
+		builder.Reply(msg.MessageID)
+	}
+	if opts.Mention && msg.IsGroupMessage() {
+		builder.At(msg.Sender.UserId).Text(" ")
 	}
 
+	chain, err := toMessageChain(message, opts.AutoEscape)
+	if err != nil {
+		return nil, err
+	}
+	builder.chain = append(builder.chain, chain...)
+
 	if msg.IsGroupMessage() {
-		err := msg.Bot.SendGroupMessage(message, msg.GroupId, explicit)
-		if err != nil {
-			return err
-		}
-	} else if msg.IsPrivateMessage() {
-		err := msg.Bot.SendPrivateMessage(message, msg.Sender.UserId, explicit)
-		if err != nil {
-			return err
-		}
+		return msg.Bot.sendMessage(ctx, GroupMessageType, msg.GroupId, builder, false)
 	}
+	return msg.Bot.sendMessage(ctx, PrivateMessageType, msg.Sender.UserId, builder, false)
+}
 
-	return nil
+// SentMessage Bot 发送的消息
+type SentMessage struct {
+	MessageId   int64
+	ForwardId   string // 合并转发 ID, 仅合并转发消息
+	MessageType string // GroupMessageType 或 PrivateMessageType
+	TargetId    int64  // 群组 ID 或用户 ID
+	Time        int64  // 发送时间
+
+	Bot *Bot
 }
 
-func (msg *Message) GetRepliedMessage() *Message {
-	re := regexp.MustCompile(`\[CQ:reply,id=(-?\d+)\]`)
-	match := re.FindStringSubmatch(msg.RawMessage)
+// Recall 撤回该消息
+func (sent *SentMessage) Recall() error {
+	return sent.Bot.DeleteMessage(sent.MessageId)
+}
 
-	if len(match) <= 1 {
-		return nil
+// Reply 引用该消息回复
+func (sent *SentMessage) Reply(message any) (*SentMessage, error) {
+	chain, err := toMessageChain(message, false)
+	if err != nil {
+		return nil, err
 	}
 
-	reMessageId, err := strconv.ParseInt(match[1], 10, 64)
-	if err != nil {
+	builder := NewMessage().Reply(sent.MessageId)
+	builder.chain = append(builder.chain, chain...)
+
+	return sent.Bot.SendMessage(sent.MessageType, sent.TargetId, builder)
+}
+
+// RecallAfter 在 d 后自动撤回该消息, 可通过返回的 Timer 取消
+func (sent *SentMessage) RecallAfter(d time.Duration) *time.Timer {
+	return time.AfterFunc(d, func() {
+		err := sent.Recall()
+		if err != nil {
+			log.Error("自动撤回消息失败:", err)
+		}
+	})
+}
+
+// ReplyMessage 回复消息
+// explicit bool 为 true 时引用原消息, 否则在群聊中 @发送者
+//
+// Deprecated: 使用 Reply
+func (msg *Message) ReplyMessage(message string, explicit bool) error {
+	_, err := msg.Reply(message, ReplyOptions{
+		Quote:   explicit,
+		Mention: !explicit,
+	})
+	return err
+}
+
+// Recall 撤回该消息
+func (msg *Message) Recall() error {
+	return msg.Bot.DeleteMessage(msg.MessageID)
+}
+
+func (msg *Message) GetRepliedMessage() *Message {
+	reMessageId, ok := msg.Chain.ReplyId()
+	if !ok {
 		return nil
 	}
 
@@ -85,6 +256,16 @@ func (msg *Message) GetRepliedMessage() *Message {
 	return reMessage
 }
 
+// GetForwardedMessages 获取消息中合并转发的内容, 消息不含合并转发时返回 nil
+func (msg *Message) GetForwardedMessages() ([]*Message, error) {
+	forwards := msg.Chain.Segments(ForwardSegment)
+	if len(forwards) == 0 {
+		return nil, nil
+	}
+
+	return msg.Bot.GetForwardMessage(forwards[0].Get("id"))
+}
+
 func buildMessageByUpdate(update *Update) *Message {
 	user := &User{
 		UserId:   update.Event.Get("sender.user_id").Int(),
@@ -94,8 +275,10 @@ func buildMessageByUpdate(update *Update) *Message {
 	msg := Message{
 		MessageType: update.Event.Get("message_type").String(),
 		MessageID:   update.Event.Get("message_id").Int(),
+		Time:        update.Event.Time,
 		Sender:      user,
 		RawMessage:  update.Event.Get("raw_message").String(),
+		Chain:       update.Event.Message(),
 
 		Bot: update.Bot,
 	}
@@ -103,11 +286,14 @@ func buildMessageByUpdate(update *Update) *Message {
 	if msg.IsGroupMessage() {
 		msg.GroupId = update.Event.Get("group_id").Int()
 		msg.GroupMember = &GroupMember{
-			User: user,
-			Card: update.Event.Get("sender.card").String(),
+			User:    user,
+			GroupId: msg.GroupId,
+			Card:    update.Event.Get("sender.card").String(),
 
 			Level: update.Event.Get("sender.level").String(),
-			Role:  update.Event.Get("sender.card").String(),
+			Role:  update.Event.Get("sender.role").String(),
+
+			Bot: update.Bot,
 		}
 	}
 
@@ -0,0 +1,251 @@
+package hareru_cq
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// MessageBuilder 消息构建器
+//
+//	chain := NewMessage().Reply(msg.MessageID).At(userId).Text(" 你好").Build()
+type MessageBuilder struct {
+	chain MessageChain
+	err   error
+}
+
+// NewMessage 创建消息构建器
+func NewMessage() *MessageBuilder {
+	return &MessageBuilder{
+		chain: make(MessageChain, 0),
+	}
+}
+
+// Segment 追加任意消息段
+func (b *MessageBuilder) Segment(seg Segment) *MessageBuilder {
+	b.chain = append(b.chain, seg)
+	return b
+}
+
+// add 追加消息段, data 为成对的 key, value
+func (b *MessageBuilder) add(segType string, data ...string) *MessageBuilder {
+	seg := Segment{
+		Type: segType,
+		Data: make(map[string]any, len(data)/2),
+	}
+	for i := 0; i+1 < len(data); i += 2 {
+		seg.Data[data[i]] = data[i+1]
+	}
+	return b.Segment(seg)
+}
+
+// Text 纯文本
+func (b *MessageBuilder) Text(text string) *MessageBuilder {
+	return b.add(TextSegment, "text", text)
+}
+
+// Textf 格式化文本
+func (b *MessageBuilder) Textf(format string, args ...any) *MessageBuilder {
+	return b.Text(fmt.Sprintf(format, args...))
+}
+
+// Face QQ 表情
+func (b *MessageBuilder) Face(id int) *MessageBuilder {
+	return b.add(FaceSegment, "id", strconv.Itoa(id))
+}
+
+// At @某人
+func (b *MessageBuilder) At(userId int64) *MessageBuilder {
+	return b.add(AtSegment, "qq", strconv.FormatInt(userId, 10))
+}
+
+// AtAll @全体成员
+func (b *MessageBuilder) AtAll() *MessageBuilder {
+	return b.add(AtSegment, "qq", "all")
+}
+
+// Image 图片
+// file string 图片文件名, 或 http(s)://, file:///, base64:// URI
+func (b *MessageBuilder) Image(file string) *MessageBuilder {
+	return b.add(ImageSegment, "file", file)
+}
+
+// ImageFrom 由本地路径, []byte, image.Image 或 io.Reader 生成图片, 见 MediaFile
+func (b *MessageBuilder) ImageFrom(source any) *MessageBuilder {
+	return b.media(ImageSegment, source)
+}
+
+// Record 语音
+// file string 同 Image
+func (b *MessageBuilder) Record(file string) *MessageBuilder {
+	return b.add(RecordSegment, "file", file)
+}
+
+// RecordFrom 由本地路径, []byte 或 io.Reader 生成语音, 见 MediaFile
+func (b *MessageBuilder) RecordFrom(source any) *MessageBuilder {
+	return b.media(RecordSegment, source)
+}
+
+// Video 短视频
+// file string 同 Image
+func (b *MessageBuilder) Video(file string) *MessageBuilder {
+	return b.add(VideoSegment, "file", file)
+}
+
+// VideoFrom 由本地路径, []byte 或 io.Reader 生成短视频, 见 MediaFile
+func (b *MessageBuilder) VideoFrom(source any) *MessageBuilder {
+	return b.media(VideoSegment, source)
+}
+
+// media 追加媒体消息段, 转换失败的错误由 Err 返回
+func (b *MessageBuilder) media(segType string, source any) *MessageBuilder {
+	file, err := MediaFile(source, segType)
+	if err != nil {
+		if b.err == nil {
+			b.err = err
+		}
+		return b
+	}
+	return b.add(segType, "file", file)
+}
+
+// Reply 回复消息, 回复消息段总是位于消息开头
+func (b *MessageBuilder) Reply(messageId int64) *MessageBuilder {
+	reply := Segment{
+		Type: ReplySegment,
+		Data: map[string]any{"id": strconv.FormatInt(messageId, 10)},
+	}
+	b.chain = append(MessageChain{reply}, b.chain...)
+	return b
+}
+
+// Rps 猜拳魔法表情
+func (b *MessageBuilder) Rps() *MessageBuilder {
+	return b.add(RpsSegment)
+}
+
+// Dice 掷骰子魔法表情
+func (b *MessageBuilder) Dice() *MessageBuilder {
+	return b.add(DiceSegment)
+}
+
+// Shake 窗口抖动 (戳一戳), 仅私聊
+func (b *MessageBuilder) Shake() *MessageBuilder {
+	return b.add(ShakeSegment)
+}
+
+// Poke 群内戳一戳 (go-cqhttp)
+func (b *MessageBuilder) Poke(userId int64) *MessageBuilder {
+	return b.add(PokeSegment, "qq", strconv.FormatInt(userId, 10))
+}
+
+// Share 链接分享
+func (b *MessageBuilder) Share(url string, title string) *MessageBuilder {
+	return b.add(ShareSegment, "url", url, "title", title)
+}
+
+// Contact 推荐好友 (contactType 为 qq) 或群 (contactType 为 group)
+func (b *MessageBuilder) Contact(contactType string, id int64) *MessageBuilder {
+	return b.add(ContactSegment, "type", contactType, "id", strconv.FormatInt(id, 10))
+}
+
+// Location 位置
+func (b *MessageBuilder) Location(lat float64, lon float64, title string) *MessageBuilder {
+	return b.add(LocationSegment,
+		"lat", strconv.FormatFloat(lat, 'f', -1, 64),
+		"lon", strconv.FormatFloat(lon, 'f', -1, 64),
+		"title", title,
+	)
+}
+
+// Music 音乐分享
+// musicType string qq, 163 或 xm
+func (b *MessageBuilder) Music(musicType string, id string) *MessageBuilder {
+	return b.add(MusicSegment, "type", musicType, "id", id)
+}
+
+// CustomMusic 自定义音乐分享
+func (b *MessageBuilder) CustomMusic(url string, audio string, title string) *MessageBuilder {
+	return b.add(MusicSegment, "type", "custom", "url", url, "audio", audio, "title", title)
+}
+
+// Forward 合并转发
+// id string 合并转发 ID
+func (b *MessageBuilder) Forward(id string) *MessageBuilder {
+	return b.add(ForwardSegment, "id", id)
+}
+
+// Xml XML 消息
+func (b *MessageBuilder) Xml(data string) *MessageBuilder {
+	return b.add(XmlSegment, "data", data)
+}
+
+// Json JSON 消息
+func (b *MessageBuilder) Json(data string) *MessageBuilder {
+	return b.add(JsonSegment, "data", data)
+}
+
+// Err 构建过程中的错误, 如媒体文件无法读取
+func (b *MessageBuilder) Err() error {
+	return b.err
+}
+
+// Build 生成消息链
+func (b *MessageBuilder) Build() MessageChain {
+	return b.chain
+}
+
+// String 生成 CQ 码字符串
+func (b *MessageBuilder) String() string {
+	return b.chain.String()
+}
+
+// String 序列化为 CQ 码字符串
+func (chain MessageChain) String() string {
+	var builder strings.Builder
+	for _, seg := range chain {
+		builder.WriteString(seg.String())
+	}
+	return builder.String()
+}
+
+// String 序列化为 CQ 码, 文本消息段输出转义后的纯文本
+func (seg Segment) String() string {
+	if seg.Type == TextSegment {
+		return EscapeCQ(seg.Get("text"), false)
+	}
+
+	keys := make([]string, 0, len(seg.Data))
+	for key := range seg.Data {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	var builder strings.Builder
+	builder.WriteString("[CQ:")
+	builder.WriteString(seg.Type)
+	for _, key := range keys {
+		builder.WriteByte(',')
+		builder.WriteString(key)
+		builder.WriteByte('=')
+		builder.WriteString(EscapeCQ(seg.Get(key), true))
+	}
+	builder.WriteByte(']')
+
+	return builder.String()
+}
+
+var (
+	cqTextEscaper  = strings.NewReplacer("&", "&amp;", "[", "&#91;", "]", "&#93;")
+	cqParamEscaper = strings.NewReplacer("&", "&amp;", "[", "&#91;", "]", "&#93;", ",", "&#44;")
+)
+
+// EscapeCQ CQ 码转义
+// inParam bool 是否为 CQ 码参数值, 参数值还需转义逗号
+func EscapeCQ(text string, inParam bool) string {
+	if inParam {
+		return cqParamEscaper.Replace(text)
+	}
+	return cqTextEscaper.Replace(text)
+}
@@ -0,0 +1,69 @@
+package hareru_cq
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestEscapeCQ(t *testing.T) {
+	tests := []struct {
+		text    string
+		inParam bool
+		want    string
+	}{
+		{"plain", false, "plain"},
+		{"[CQ:at,qq=all]", false, "&#91;CQ:at,qq=all&#93;"},
+		{"a & b, c", false, "a &amp; b, c"},
+		{"&#91;", false, "&amp;#91;"},
+		{"[CQ:at,qq=all]", true, "&#91;CQ:at&#44;qq=all&#93;"},
+		{"a & b, c", true, "a &amp; b&#44; c"},
+		{"&#44;", true, "&amp;#44;"},
+	}
+
+	for _, tt := range tests {
+		if got := EscapeCQ(tt.text, tt.inParam); got != tt.want {
+			t.Errorf("EscapeCQ(%q, %v) = %q, want %q", tt.text, tt.inParam, got, tt.want)
+		}
+	}
+}
+
+func TestMessageBuilderString(t *testing.T) {
+	message := NewMessage().
+		Reply(1).
+		At(2).
+		Text(" [CQ:at,qq=all] & co, ltd").
+		Share("https://a.com/?x=1&y=2", "[t],t").
+		String()
+
+	want := "[CQ:reply,id=1][CQ:at,qq=2] &#91;CQ:at,qq=all&#93; &amp; co, ltd" +
+		"[CQ:share,title=&#91;t&#93;&#44;t,url=https://a.com/?x=1&amp;y=2]"
+	if message != want {
+		t.Errorf("String() = %q, want %q", message, want)
+	}
+}
+
+func TestCQStringRoundTrip(t *testing.T) {
+	messages := []string{
+		"",
+		"hello",
+		"[CQ:share,title=a&#44;b,url=x&amp;y]",
+		"&#91;CQ:at,qq=1&#93; &amp;&#91;&#93;, [CQ:face,id=1]tail",
+		"[CQ:at,qq=1][CQ:image,file=a&#91;1&#93;.png]",
+	}
+
+	for _, message := range messages {
+		if got := ParseCQString(message).String(); got != message {
+			t.Errorf("round trip of %q = %q", message, got)
+		}
+	}
+
+	// 用户文本经序列化与解析后保持不变, 不会被识别为 CQ 码
+	texts := []string{"[CQ:at,qq=all]", "a&b,c", "&#91;&amp;&#44;", "[", "]"}
+	for _, text := range texts {
+		chain := NewMessage().Text(text).Share(text, text).Build()
+		parsed := ParseCQString(chain.String())
+		if !reflect.DeepEqual(parsed, chain) {
+			t.Errorf("text %q: parsed %#v, want %#v", text, parsed, chain)
+		}
+	}
+}
@@ -0,0 +1,80 @@
+package hareru_cq
+
+import "testing"
+
+func TestMessageReply(t *testing.T) {
+	fake := newFakeCqHttp(t)
+	bot := newTestBot(t, fake)
+	bot.MessageFormat = StringMessageFormat
+
+	groupUpdate := newEventUpdate(t, map[string]any{
+		"post_type": "message", "message_type": "group", "message_id": 5, "group_id": 100, "user_id": 1,
+		"sender": map[string]any{"user_id": 1}, "message": "hi",
+	})
+	groupUpdate.Bot = bot
+	group := buildMessageByUpdate(groupUpdate)
+
+	privateUpdate := newEventUpdate(t, map[string]any{
+		"post_type": "message", "message_type": "private", "message_id": 6, "user_id": 1,
+		"sender": map[string]any{"user_id": 1}, "message": "hi",
+	})
+	privateUpdate.Bot = bot
+	private := buildMessageByUpdate(privateUpdate)
+
+	tests := []struct {
+		name    string
+		reply   func() error
+		action  string
+		message string
+	}{
+		{"quote", func() error {
+			_, err := group.Reply("hi [CQ:face,id=1]", ReplyOptions{Quote: true})
+			return err
+		}, "send_group_msg", "[CQ:reply,id=5]hi [CQ:face,id=1]"},
+		{"mention escaped", func() error {
+			_, err := group.Reply("[CQ:at,qq=all] & x", ReplyOptions{Mention: true, AutoEscape: true})
+			return err
+		}, "send_group_msg", "[CQ:at,qq=1] &#91;CQ:at,qq=all&#93; &amp; x"},
+		{"quote and mention", func() error {
+			_, err := group.Reply(NewMessage().Text("["), ReplyOptions{Quote: true, Mention: true})
+			return err
+		}, "send_group_msg", "[CQ:reply,id=5][CQ:at,qq=1] &#91;"},
+		{"deprecated explicit", func() error {
+			return group.ReplyMessage("x", true)
+		}, "send_group_msg", "[CQ:reply,id=5]x"},
+		{"deprecated mention", func() error {
+			return group.ReplyMessage("x", false)
+		}, "send_group_msg", "[CQ:at,qq=1] x"},
+		{"private no mention", func() error {
+			_, err := private.Reply("x", ReplyOptions{Quote: true, Mention: true})
+			return err
+		}, "send_private_msg", "[CQ:reply,id=6]x"},
+		{"private deprecated", func() error {
+			return private.ReplyMessage("x", false)
+		}, "send_private_msg", "x"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.reply()
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			request := fake.waitRequest(t, tt.action)
+			params := request.Get("params")
+			if got := params.Get("message").String(); got != tt.message {
+				t.Errorf("message = %q, want %q", got, tt.message)
+			}
+			if params.Get("auto_escape").Bool() {
+				t.Error("auto_escape = true")
+			}
+			if tt.action == "send_group_msg" && params.Get("group_id").Int() != 100 {
+				t.Errorf("group_id = %s", params.Get("group_id").Raw)
+			}
+			if tt.action == "send_private_msg" && params.Get("user_id").Int() != 1 {
+				t.Errorf("user_id = %s", params.Get("user_id").Raw)
+			}
+		})
+	}
+}
@@ -0,0 +1,393 @@
+package hareru_cq
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+)
+
+const (
+	ApproveIncreaseSubType = "approve" // GroupIncreaseNotice.SubType 管理员同意入群
+	InviteIncreaseSubType  = "invite"  // GroupIncreaseNotice.SubType 管理员邀请入群
+
+	LeaveDecreaseSubType  = "leave"   // GroupDecreaseNotice.SubType 主动退群
+	KickDecreaseSubType   = "kick"    // GroupDecreaseNotice.SubType 成员被踢
+	KickMeDecreaseSubType = "kick_me" // GroupDecreaseNotice.SubType 登录号被踢
+
+	SetAdminSubType   = "set"   // GroupAdminNotice.SubType 设置管理员
+	UnsetAdminSubType = "unset" // GroupAdminNotice.SubType 取消管理员
+
+	BanSubType     = "ban"      // GroupBanNotice.SubType 禁言
+	LiftBanSubType = "lift_ban" // GroupBanNotice.SubType 解除禁言
+
+	AddEssenceSubType    = "add"    // EssenceNotice.SubType 添加精华消息
+	DeleteEssenceSubType = "delete" // EssenceNotice.SubType 移出精华消息
+)
+
+// Notice 通知事件, 为本文件中的 *XxxNotice
+type Notice interface {
+	NoticeType() string // 通知类型, 同 EventFilter 中的事件常量
+}
+
+// GroupRecallNotice 群消息撤回
+type GroupRecallNotice struct {
+	Time       int64 `json:"time"`
+	SelfId     int64 `json:"self_id"`
+	GroupId    int64 `json:"group_id"`
+	UserId     int64 `json:"user_id"`     // 消息发送者
+	OperatorId int64 `json:"operator_id"` // 撤回操作者
+	MessageId  int64 `json:"message_id"`
+
+	Bot *Bot `json:"-"`
+}
+
+// FriendRecallNotice 好友消息撤回
+type FriendRecallNotice struct {
+	Time      int64 `json:"time"`
+	SelfId    int64 `json:"self_id"`
+	UserId    int64 `json:"user_id"`
+	MessageId int64 `json:"message_id"`
+
+	Bot *Bot `json:"-"`
+}
+
+// GroupIncreaseNotice 群成员增加
+type GroupIncreaseNotice struct {
+	Time       int64  `json:"time"`
+	SelfId     int64  `json:"self_id"`
+	SubType    string `json:"sub_type"` // ApproveIncreaseSubType 或 InviteIncreaseSubType
+	GroupId    int64  `json:"group_id"`
+	UserId     int64  `json:"user_id"`     // 加入者
+	OperatorId int64  `json:"operator_id"` // 操作者
+
+	Bot *Bot `json:"-"`
+}
+
+// GroupDecreaseNotice 群成员减少
+type GroupDecreaseNotice struct {
+	Time       int64  `json:"time"`
+	SelfId     int64  `json:"self_id"`
+	SubType    string `json:"sub_type"` // LeaveDecreaseSubType, KickDecreaseSubType 或 KickMeDecreaseSubType
+	GroupId    int64  `json:"group_id"`
+	UserId     int64  `json:"user_id"`     // 离开者
+	OperatorId int64  `json:"operator_id"` // 操作者, 主动退群时与 UserId 相同
+
+	Bot *Bot `json:"-"`
+}
+
+// GroupAdminNotice 群管理员变动
+type GroupAdminNotice struct {
+	Time    int64  `json:"time"`
+	SelfId  int64  `json:"self_id"`
+	SubType string `json:"sub_type"` // SetAdminSubType 或 UnsetAdminSubType
+	GroupId int64  `json:"group_id"`
+	UserId  int64  `json:"user_id"`
+
+	Bot *Bot `json:"-"`
+}
+
+// GroupBanNotice 群禁言
+type GroupBanNotice struct {
+	Time       int64  `json:"time"`
+	SelfId     int64  `json:"self_id"`
+	SubType    string `json:"sub_type"` // BanSubType 或 LiftBanSubType
+	GroupId    int64  `json:"group_id"`
+	UserId     int64  `json:"user_id"`     // 被禁言者, 全员禁言时为 0
+	OperatorId int64  `json:"operator_id"` // 操作者
+	Duration   int64  `json:"duration"`    // 禁言时长 (秒)
+
+	Bot *Bot `json:"-"`
+}
+
+// GroupFile 群文件信息
+type GroupFile struct {
+	Id    string `json:"id"`
+	Name  string `json:"name"`
+	Size  int64  `json:"size"` // 字节
+	Busid int64  `json:"busid"`
+}
+
+// GroupUploadNotice 群文件上传
+type GroupUploadNotice struct {
+	Time    int64      `json:"time"`
+	SelfId  int64      `json:"self_id"`
+	GroupId int64      `json:"group_id"`
+	UserId  int64      `json:"user_id"`
+	File    *GroupFile `json:"file"`
+
+	Bot *Bot `json:"-"`
+}
+
+// GroupCardNotice 群名片变更 (go-cqhttp), 仅在成员发言后触发
+type GroupCardNotice struct {
+	Time    int64  `json:"time"`
+	SelfId  int64  `json:"self_id"`
+	GroupId int64  `json:"group_id"`
+	UserId  int64  `json:"user_id"`
+	CardNew string `json:"card_new"`
+	CardOld string `json:"card_old"`
+
+	Bot *Bot `json:"-"`
+}
+
+// EssenceNotice 精华消息变动 (go-cqhttp)
+type EssenceNotice struct {
+	Time       int64  `json:"time"`
+	SelfId     int64  `json:"self_id"`
+	SubType    string `json:"sub_type"` // AddEssenceSubType 或 DeleteEssenceSubType
+	GroupId    int64  `json:"group_id"`
+	SenderId   int64  `json:"sender_id"`   // 消息发送者
+	OperatorId int64  `json:"operator_id"` // 操作者
+	MessageId  int64  `json:"message_id"`
+
+	Bot *Bot `json:"-"`
+}
+
+// FriendAddNotice 新好友添加
+type FriendAddNotice struct {
+	Time   int64 `json:"time"`
+	SelfId int64 `json:"self_id"`
+	UserId int64 `json:"user_id"`
+
+	Bot *Bot `json:"-"`
+}
+
+// PokeNotice 戳一戳 (notify), 好友戳一戳时 GroupId 为 0
+type PokeNotice struct {
+	Time     int64 `json:"time"`
+	SelfId   int64 `json:"self_id"`
+	GroupId  int64 `json:"group_id"`
+	SenderId int64 `json:"sender_id"` // 好友戳一戳的发送者 (go-cqhttp)
+	UserId   int64 `json:"user_id"`   // 发送者
+	TargetId int64 `json:"target_id"` // 被戳者
+
+	Bot *Bot `json:"-"`
+}
+
+// LuckyKingNotice 群红包运气王 (notify)
+type LuckyKingNotice struct {
+	Time     int64 `json:"time"`
+	SelfId   int64 `json:"self_id"`
+	GroupId  int64 `json:"group_id"`
+	UserId   int64 `json:"user_id"`   // 红包发送者
+	TargetId int64 `json:"target_id"` // 运气王
+
+	Bot *Bot `json:"-"`
+}
+
+// HonorNotice 群成员荣誉变更 (notify)
+type HonorNotice struct {
+	Time      int64  `json:"time"`
+	SelfId    int64  `json:"self_id"`
+	GroupId   int64  `json:"group_id"`
+	HonorType string `json:"honor_type"` // TalkativeHonor, PerformerHonor 或 EmotionHonor
+	UserId    int64  `json:"user_id"`
+
+	Bot *Bot `json:"-"`
+}
+
+// TitleNotice 群成员头衔变更 (notify, go-cqhttp)
+type TitleNotice struct {
+	Time    int64  `json:"time"`
+	SelfId  int64  `json:"self_id"`
+	GroupId int64  `json:"group_id"`
+	UserId  int64  `json:"user_id"`
+	Title   string `json:"title"` // 获得的新头衔
+
+	Bot *Bot `json:"-"`
+}
+
+// Device 客户端信息
+type Device struct {
+	AppId      int64  `json:"app_id"`
+	DeviceName string `json:"device_name"`
+	DeviceKind string `json:"device_kind"`
+}
+
+// ClientStatusNotice 其他客户端在线状态变更 (go-cqhttp)
+type ClientStatusNotice struct {
+	Time   int64   `json:"time"`
+	SelfId int64   `json:"self_id"`
+	Client *Device `json:"client"`
+	Online bool    `json:"online"`
+
+	Bot *Bot `json:"-"`
+}
+
+// OfflineFile 离线文件信息
+type OfflineFile struct {
+	Name string `json:"name"`
+	Size int64  `json:"size"` // 字节
+	Url  string `json:"url"`  // 下载链接
+}
+
+// OfflineFileNotice 接收到离线文件 (go-cqhttp)
+type OfflineFileNotice struct {
+	Time   int64        `json:"time"`
+	SelfId int64        `json:"self_id"`
+	UserId int64        `json:"user_id"`
+	File   *OfflineFile `json:"file"`
+
+	Bot *Bot `json:"-"`
+}
+
+func (n *GroupRecallNotice) NoticeType() string   { return GroupRecallEvent }
+func (n *FriendRecallNotice) NoticeType() string  { return FriendRecallEvent }
+func (n *GroupIncreaseNotice) NoticeType() string { return GroupIncreaseEvent }
+func (n *GroupDecreaseNotice) NoticeType() string { return GroupDecreaseEvent }
+func (n *GroupAdminNotice) NoticeType() string    { return GroupAdminEvent }
+func (n *GroupBanNotice) NoticeType() string      { return GroupBanEvent }
+func (n *GroupUploadNotice) NoticeType() string   { return GroupUploadEvent }
+func (n *GroupCardNotice) NoticeType() string     { return GroupCardEvent }
+func (n *EssenceNotice) NoticeType() string       { return EssenceEvent }
+func (n *FriendAddNotice) NoticeType() string     { return FriendAddEvent }
+func (n *PokeNotice) NoticeType() string          { return PokeEvent }
+func (n *LuckyKingNotice) NoticeType() string     { return LuckyKingEvent }
+func (n *HonorNotice) NoticeType() string         { return HonorEvent }
+func (n *TitleNotice) NoticeType() string         { return TitleEvent }
+func (n *ClientStatusNotice) NoticeType() string  { return ClientStatusEvent }
+func (n *OfflineFileNotice) NoticeType() string   { return OfflineFileEvent }
+
+// IsSelf 被撤回的是否为 Bot 自己的消息
+func (n *GroupRecallNotice) IsSelf() bool {
+	return n.UserId == n.SelfId
+}
+
+// GetMessage 获取被撤回的消息
+func (n *GroupRecallNotice) GetMessage() (*Message, error) {
+	return n.Bot.GetMessage(n.MessageId, false)
+}
+
+// GetMessage 获取被撤回的消息
+func (n *FriendRecallNotice) GetMessage() (*Message, error) {
+	return n.Bot.GetMessage(n.MessageId, false)
+}
+
+// IsSelf 加入者是否为 Bot 自己
+func (n *GroupIncreaseNotice) IsSelf() bool {
+	return n.UserId == n.SelfId
+}
+
+// IsSelf 离开者是否为 Bot 自己
+func (n *GroupDecreaseNotice) IsSelf() bool {
+	return n.SubType == KickMeDecreaseSubType || n.UserId == n.SelfId
+}
+
+// IsSet 是否为设置管理员
+func (n *GroupAdminNotice) IsSet() bool {
+	return n.SubType == SetAdminSubType
+}
+
+// IsLift 是否为解除禁言
+func (n *GroupBanNotice) IsLift() bool {
+	return n.SubType == LiftBanSubType
+}
+
+// IsWholeBan 是否为全员禁言
+func (n *GroupBanNotice) IsWholeBan() bool {
+	return n.UserId == 0
+}
+
+// BanDuration 禁言时长
+func (n *GroupBanNotice) BanDuration() time.Duration {
+	return time.Duration(n.Duration) * time.Second
+}
+
+// IsAdd 是否为添加精华消息
+func (n *EssenceNotice) IsAdd() bool {
+	return n.SubType == AddEssenceSubType
+}
+
+// IsGroup 是否为群内戳一戳
+func (n *PokeNotice) IsGroup() bool {
+	return n.GroupId != 0
+}
+
+// IsTargetSelf 被戳者是否为 Bot 自己
+func (n *PokeNotice) IsTargetSelf() bool {
+	return n.TargetId == n.SelfId
+}
+
+// PokeBack 戳回发送者
+func (n *PokeNotice) PokeBack() error {
+	return n.Bot.SendPoke(n.GroupId, n.UserId)
+}
+
+// IsTargetSelf 运气王是否为 Bot 自己
+func (n *LuckyKingNotice) IsTargetSelf() bool {
+	return n.TargetId == n.SelfId
+}
+
+// noticeConstructors notice_type 对应的通知构造函数, notify 通知以 sub_type 为键
+var noticeConstructors = map[string]func(bot *Bot) Notice{
+	GroupRecallEvent:   func(bot *Bot) Notice { return &GroupRecallNotice{Bot: bot} },
+	FriendRecallEvent:  func(bot *Bot) Notice { return &FriendRecallNotice{Bot: bot} },
+	GroupIncreaseEvent: func(bot *Bot) Notice { return &GroupIncreaseNotice{Bot: bot} },
+	GroupDecreaseEvent: func(bot *Bot) Notice { return &GroupDecreaseNotice{Bot: bot} },
+	GroupAdminEvent:    func(bot *Bot) Notice { return &GroupAdminNotice{Bot: bot} },
+	GroupBanEvent:      func(bot *Bot) Notice { return &GroupBanNotice{Bot: bot} },
+	GroupUploadEvent:   func(bot *Bot) Notice { return &GroupUploadNotice{Bot: bot} },
+	GroupCardEvent:     func(bot *Bot) Notice { return &GroupCardNotice{Bot: bot} },
+	EssenceEvent:       func(bot *Bot) Notice { return &EssenceNotice{Bot: bot} },
+	FriendAddEvent:     func(bot *Bot) Notice { return &FriendAddNotice{Bot: bot} },
+	ClientStatusEvent:  func(bot *Bot) Notice { return &ClientStatusNotice{Bot: bot} },
+	OfflineFileEvent:   func(bot *Bot) Notice { return &OfflineFileNotice{Bot: bot} },
+
+	PokeEvent:      func(bot *Bot) Notice { return &PokeNotice{Bot: bot} },
+	LuckyKingEvent: func(bot *Bot) Notice { return &LuckyKingNotice{Bot: bot} },
+	HonorEvent:     func(bot *Bot) Notice { return &HonorNotice{Bot: bot} },
+	TitleEvent:     func(bot *Bot) Notice { return &TitleNotice{Bot: bot} },
+}
+
+// Notice 由通知事件构建对应的 *XxxNotice, 非通知事件或未知通知类型返回 nil
+func (update *Update) Notice() Notice {
+	if update.Event.Type != "notice" {
+		return nil
+	}
+
+	noticeType := update.Event.Get("notice_type").String()
+	if noticeType == NotifyEvent {
+		noticeType = update.Event.SubType
+	}
+
+	constructor, ok := noticeConstructors[noticeType]
+	if !ok {
+		return nil
+	}
+
+	notice := constructor(update.Bot)
+	err := json.Unmarshal([]byte(update.Event.Json.Raw), notice)
+	if err != nil {
+		return nil
+	}
+
+	return notice
+}
+
+// NoticeOf 由通知事件构建类型为 N 的通知, 类型不符时 ok 为 false
+//
+//	if ban, ok := NoticeOf[*GroupBanNotice](update); ok { ... }
+func NoticeOf[N Notice](update *Update) (notice N, ok bool) {
+	notice, ok = update.Notice().(N)
+	return notice, ok
+}
+
+// SendPoke 戳一戳 (go-cqhttp)
+// groupId int64 群组 ID, 为 0 时戳好友
+// userId int64 被戳者
+func (bot *Bot) SendPoke(groupId int64, userId int64) error {
+	return bot.SendPokeCtx(context.Background(), groupId, userId)
+}
+
+// SendPokeCtx 戳一戳
+func (bot *Bot) SendPokeCtx(ctx context.Context, groupId int64, userId int64) error {
+	if groupId != 0 {
+		_, err := bot.sendMessage(ctx, GroupMessageType, groupId, NewMessage().Poke(userId), false)
+		return err
+	}
+
+	_, err := bot.call(ctx, "friend_poke", map[string]interface{}{
+		"user_id": userId,
+	})
+	return err
+}
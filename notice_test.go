@@ -0,0 +1,94 @@
+package hareru_cq
+
+import "testing"
+
+func TestUpdateNotice(t *testing.T) {
+	bot := &Bot{}
+
+	ban := newEventUpdate(t, map[string]any{
+		"time": 1, "self_id": 10, "post_type": "notice", "notice_type": "group_ban", "sub_type": "ban",
+		"group_id": 100, "user_id": 1, "operator_id": 2, "duration": 600,
+	})
+	ban.Bot = bot
+	notice, ok := NoticeOf[*GroupBanNotice](ban)
+	if !ok {
+		t.Fatalf("NoticeOf[*GroupBanNotice] failed: %#v", ban.Notice())
+	}
+	want := GroupBanNotice{Time: 1, SelfId: 10, SubType: BanSubType, GroupId: 100, UserId: 1, OperatorId: 2, Duration: 600, Bot: bot}
+	if *notice != want {
+		t.Errorf("GroupBanNotice = %+v, want %+v", *notice, want)
+	}
+	if _, ok := NoticeOf[*GroupUploadNotice](ban); ok {
+		t.Error("NoticeOf[*GroupUploadNotice] ok for group_ban")
+	}
+
+	upload := newEventUpdate(t, map[string]any{
+		"post_type": "notice", "notice_type": "group_upload", "group_id": 100, "user_id": 1,
+		"file": map[string]any{"id": "/abc", "name": "a.zip", "size": 1024, "busid": 102},
+	})
+	uploadNotice, ok := NoticeOf[*GroupUploadNotice](upload)
+	if !ok {
+		t.Fatal("NoticeOf[*GroupUploadNotice] failed")
+	}
+	if file := uploadNotice.File; file == nil || *file != (GroupFile{Id: "/abc", Name: "a.zip", Size: 1024, Busid: 102}) {
+		t.Errorf("GroupUploadNotice.File = %+v", file)
+	}
+
+	// notify 通知按 sub_type 构建
+	poke := newEventUpdate(t, map[string]any{
+		"post_type": "notice", "notice_type": "notify", "sub_type": "poke",
+		"group_id": 100, "user_id": 1, "target_id": 10,
+	})
+	pokeNotice, ok := NoticeOf[*PokeNotice](poke)
+	if !ok {
+		t.Fatalf("NoticeOf[*PokeNotice] failed: %#v", poke.Notice())
+	}
+	if pokeNotice.GroupId != 100 || pokeNotice.UserId != 1 || pokeNotice.TargetId != 10 {
+		t.Errorf("PokeNotice = %+v", pokeNotice)
+	}
+	if pokeNotice.NoticeType() != PokeEvent {
+		t.Errorf("NoticeType() = %q, want %q", pokeNotice.NoticeType(), PokeEvent)
+	}
+	if _, ok := NoticeOf[*LuckyKingNotice](poke); ok {
+		t.Error("NoticeOf[*LuckyKingNotice] ok for poke")
+	}
+
+	unknown := newEventUpdate(t, map[string]any{"post_type": "notice", "notice_type": "unknown_notice"})
+	if notice := unknown.Notice(); notice != nil {
+		t.Errorf("Notice() = %#v for unknown notice, want nil", notice)
+	}
+	unknownNotify := newEventUpdate(t, map[string]any{"post_type": "notice", "notice_type": "notify", "sub_type": "unknown"})
+	if notice := unknownNotify.Notice(); notice != nil {
+		t.Errorf("Notice() = %#v for unknown notify, want nil", notice)
+	}
+	if notice := newMessageUpdate(t, "hi").Notice(); notice != nil {
+		t.Errorf("Notice() = %#v for message, want nil", notice)
+	}
+}
+
+func TestNoticeHandler(t *testing.T) {
+	var handled *PokeNotice
+	handler := NewNoticeHandler(func(update *Update, notice *PokeNotice) any {
+		handled = notice
+		return nil
+	})
+
+	poke := newEventUpdate(t, map[string]any{"post_type": "notice", "notice_type": "notify", "sub_type": "poke", "target_id": 10})
+	ban := newEventUpdate(t, map[string]any{"post_type": "notice", "notice_type": "group_ban", "sub_type": "ban"})
+
+	if handler.CheckUpdate(ban) {
+		t.Error("CheckUpdate(group_ban) = true")
+	}
+	if !handler.CheckUpdate(poke) {
+		t.Fatal("CheckUpdate(poke) = false")
+	}
+	handler.HandleUpdate(poke)
+	if handled == nil || handled.TargetId != 10 {
+		t.Errorf("handled = %+v", handled)
+	}
+
+	handler.Filter = FilterFunc(func(update *Update) bool { return update.Event.Get("group_id").Int() == 100 })
+	if handler.CheckUpdate(poke) {
+		t.Error("CheckUpdate ignored Filter")
+	}
+}
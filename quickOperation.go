@@ -0,0 +1,78 @@
+package hareru_cq
+
+import (
+	"context"
+	"encoding/json"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// QuickOperation 快速操作
+// Handler 返回 *QuickOperation 时, HTTP POST 上报以响应体回传, WebSocket 则调用 .handle_quick_operation
+type QuickOperation struct {
+	Reply       any   `json:"reply,omitempty"`        // 回复内容 (消息事件)
+	AutoEscape  bool  `json:"auto_escape,omitempty"`  // 回复内容是否作为纯文本发送
+	AtSender    *bool `json:"at_sender,omitempty"`    // 是否 @ 发送者 (群聊, 默认 true)
+	Delete      bool  `json:"delete,omitempty"`       // 撤回该消息 (群聊)
+	Kick        bool  `json:"kick,omitempty"`         // 踢出发送者 (群聊)
+	Ban         bool  `json:"ban,omitempty"`          // 禁言发送者 (群聊)
+	BanDuration int64 `json:"ban_duration,omitempty"` // 禁言时长, 单位秒
+
+	Approve *bool  `json:"approve,omitempty"` // 是否同意请求 (请求事件)
+	Remark  string `json:"remark,omitempty"`  // 好友备注
+	Reason  string `json:"reason,omitempty"`  // 拒绝理由
+}
+
+// QuickReply 回复消息
+func QuickReply(message any) *QuickOperation {
+	return &QuickOperation{Reply: message}
+}
+
+// QuickApprove 同意请求
+func QuickApprove(remark string) *QuickOperation {
+	approve := true
+	return &QuickOperation{Approve: &approve, Remark: remark}
+}
+
+// QuickReject 拒绝请求
+func QuickReject(reason string) *QuickOperation {
+	approve := false
+	return &QuickOperation{Approve: &approve, Reason: reason}
+}
+
+// quickOperationOf 取 Handler 返回值中的快速操作
+func quickOperationOf(result any) *QuickOperation {
+	switch op := result.(type) {
+	case *QuickOperation:
+		return op
+	case QuickOperation:
+		return &op
+	}
+	return nil
+}
+
+// handleQuickOperation 执行快速操作
+func (update *Update) handleQuickOperation(op *QuickOperation) {
+	if update.quickReply != nil {
+		select {
+		case update.quickReply <- op:
+		default:
+			if op != nil {
+				log.Warnln("HTTP POST 上报仅支持一个快速操作, 已忽略")
+			}
+		}
+		return
+	}
+
+	if op == nil {
+		return
+	}
+
+	_, err := update.Bot.call(context.Background(), ".handle_quick_operation", map[string]interface{}{
+		"context":   json.RawMessage(update.Event.Json.Raw),
+		"operation": op,
+	})
+	if err != nil {
+		log.Error("快速操作失败:", err)
+	}
+}
@@ -0,0 +1,146 @@
+package hareru_cq
+
+import (
+	"errors"
+	"math/rand"
+	"time"
+
+	"github.com/gorilla/websocket"
+	log "github.com/sirupsen/logrus"
+)
+
+// connectionLost 处理连接断开: 关闭全部连接, 通知断开并开始重连
+// 正向 WebSocket 由 Client 重连, 反向 WebSocket 等待 cqhttp 重新连接
+func (c *Client) connectionLost(conn *websocket.Conn, err error) {
+	c.connLock.Lock()
+	if c.closed || (conn != c.ActConn && conn != c.EventConn) {
+		c.connLock.Unlock()
+		return
+	}
+
+	wasConnected := c.connected
+	c.connected = false
+	c.closeConns()
+	c.connLock.Unlock()
+
+	log.Warnln("与 cqHttp 的连接断开:", err)
+
+	if wasConnected {
+		c.notifyDisconnect(err)
+	}
+
+	if c.Mode == ForwardWsMode {
+		go c.reconnect()
+	}
+}
+
+// closeConns 关闭并移除当前连接, 调用方需持有 connLock
+func (c *Client) closeConns() {
+	if c.ActConn != nil {
+		_ = c.ActConn.Close()
+	}
+	if c.EventConn != nil && c.EventConn != c.ActConn {
+		_ = c.EventConn.Close()
+	}
+
+	c.ActConn = nil
+	c.EventConn = nil
+}
+
+// reconnect 以带抖动的指数退避重连 cqhttp
+func (c *Client) reconnect() {
+	interval := c.ReconnectInterval
+
+	for attempt := 1; ; attempt++ {
+		wait := jitter(interval)
+		log.Infof("%s 后进行第 %d 次重连", wait.Round(time.Millisecond), attempt)
+		time.Sleep(wait)
+
+		if c.isClosed() {
+			return
+		}
+
+		actConn, eventConn, err := c.dialConns()
+		if err == nil {
+			c.setConns(actConn, eventConn)
+			log.Infoln("cqHttp 重连成功")
+			c.notifyConnect()
+			return
+		}
+
+		// AccessToken 被拒绝时重试无意义, 停止重连; 断开已在 connectionLost 中通知
+		var authErr *AuthFailedErr
+		if errors.As(err, &authErr) {
+			log.Error("cqHttp 拒绝了 AccessToken, 停止重连:", err)
+			c.connLock.Lock()
+			c.authErr = err
+			c.connLock.Unlock()
+			c.notifyAuthFailed(err)
+			return
+		}
+
+		interval *= 2
+		if interval > c.MaxReconnectInterval {
+			interval = c.MaxReconnectInterval
+		}
+	}
+}
+
+// jitter 在 [d/2, 3d/2) 内随机取值
+func jitter(d time.Duration) time.Duration {
+	if d <= 0 {
+		return 0
+	}
+	return d/2 + time.Duration(rand.Int63n(int64(d)))
+}
+
+func (c *Client) isClosed() bool {
+	c.connLock.Lock()
+	defer c.connLock.Unlock()
+	return c.closed
+}
+
+// notifyConnect 通知重连成功
+func (c *Client) notifyConnect() {
+	for _, hook := range c.onConnect {
+		go hook()
+	}
+}
+
+// notifyDisconnect 通知连接断开
+func (c *Client) notifyDisconnect(err error) {
+	for _, hook := range c.onDisconnect {
+		go hook(err)
+	}
+}
+
+// notifyAuthFailed 通知 AccessToken 被拒绝
+func (c *Client) notifyAuthFailed(err error) {
+	for _, hook := range c.onAuthFailed {
+		go hook(err)
+	}
+}
+
+// keepAlive 定期发送 ping, 直到连接关闭
+func (c *Client) keepAlive(conn *websocket.Conn) {
+	if c.PingInterval <= 0 {
+		return
+	}
+
+	ticker := time.NewTicker(c.PingInterval)
+	defer ticker.Stop()
+
+	for range ticker.C {
+		err := conn.WriteControl(websocket.PingMessage, nil, time.Now().Add(c.PingInterval))
+		if err != nil {
+			return
+		}
+	}
+}
+
+// extendDeadline 延长读超时
+func (c *Client) extendDeadline(conn *websocket.Conn) {
+	if c.PingInterval > 0 {
+		_ = conn.SetReadDeadline(time.Now().Add(2 * c.PingInterval))
+	}
+}
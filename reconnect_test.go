@@ -0,0 +1,79 @@
+package hareru_cq
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+func TestReconnectStopsOnAuthFailure(t *testing.T) {
+	var rejected atomic.Int32
+	var revoked atomic.Bool
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if revoked.Load() {
+			rejected.Add(1)
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+
+		conn, err := websocket.Upgrade(w, r, nil, 0, 0)
+		if err != nil {
+			return
+		}
+		// 撤销 token 后断开, 触发重连
+		revoked.Store(true)
+		_ = conn.Close()
+	}))
+	defer server.Close()
+
+	client := NewClient("ws"+strings.TrimPrefix(server.URL, "http"), "token")
+	client.Universal = true
+	client.ReconnectInterval = time.Millisecond
+	client.MaxReconnectInterval = time.Millisecond
+
+	disconnected := make(chan error, 4)
+	authFailed := make(chan error, 4)
+	client.onDisconnect = append(client.onDisconnect, func(err error) { disconnected <- err })
+	client.onAuthFailed = append(client.onAuthFailed, func(err error) { authFailed <- err })
+
+	err := client.Init()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer client.Close()
+
+	var authErr *AuthFailedErr
+	select {
+	case err = <-authFailed:
+		if !errors.As(err, &authErr) {
+			t.Errorf("auth failure hook error = %v, want AuthFailedErr", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("AuthFailedErr not reported through the auth failure hook")
+	}
+
+	// 同一次断开只通知一次
+	time.Sleep(50 * time.Millisecond)
+	if len(disconnected) != 1 {
+		t.Errorf("disconnect notified %d times, want 1", len(disconnected))
+	}
+
+	// 停止重连后不再尝试连接
+	attempts := rejected.Load()
+	time.Sleep(50 * time.Millisecond)
+	if rejected.Load() != attempts {
+		t.Errorf("reconnect continued after auth failure: %d -> %d attempts", attempts, rejected.Load())
+	}
+
+	err = client.writeRequest(context.Background(), &CqRequest{Action: "get_status"})
+	if !errors.As(err, &authErr) {
+		t.Errorf("writeRequest error = %v, want AuthFailedErr", err)
+	}
+}
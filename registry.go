@@ -0,0 +1,60 @@
+package hareru_cq
+
+import "sync"
+
+// responseRegistry 按 echo 关联 Action 请求与响应, 可并发使用
+type responseRegistry struct {
+	lock    sync.Mutex
+	pending map[string]chan *CqResponse
+}
+
+func newResponseRegistry() *responseRegistry {
+	return &responseRegistry{
+		pending: make(map[string]chan *CqResponse),
+	}
+}
+
+// register 登记 echo, 须在发送请求前调用, 以免响应先于登记到达
+// 连接断开时返回的 channel 会被关闭
+func (r *responseRegistry) register(echo string) <-chan *CqResponse {
+	resChan := make(chan *CqResponse, 1)
+
+	r.lock.Lock()
+	r.pending[echo] = resChan
+	r.lock.Unlock()
+
+	return resChan
+}
+
+// cancel 注销 echo
+func (r *responseRegistry) cancel(echo string) {
+	r.lock.Lock()
+	delete(r.pending, echo)
+	r.lock.Unlock()
+}
+
+// resolve 投递响应, echo 未登记时返回 false
+func (r *responseRegistry) resolve(res *CqResponse) bool {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	resChan, ok := r.pending[res.Echo]
+	if !ok {
+		return false
+	}
+
+	delete(r.pending, res.Echo)
+	resChan <- res
+	return true
+}
+
+// failAll 关闭所有等待中的 channel
+func (r *responseRegistry) failAll() {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	for echo, resChan := range r.pending {
+		close(resChan)
+		delete(r.pending, echo)
+	}
+}
@@ -0,0 +1,52 @@
+package hareru_cq
+
+import "testing"
+
+func TestRegistryResolveBeforeRead(t *testing.T) {
+	registry := newResponseRegistry()
+	resChan := registry.register("1")
+
+	// 响应先于调用方读取到达, 不应阻塞
+	if !registry.resolve(&CqResponse{Echo: "1", Status: "ok"}) {
+		t.Fatal("resolve() = false for a registered echo")
+	}
+
+	res, ok := <-resChan
+	if !ok || res.Echo != "1" {
+		t.Fatalf("received %+v, %v", res, ok)
+	}
+
+	if registry.resolve(&CqResponse{Echo: "1"}) {
+		t.Error("resolve() = true for an already resolved echo")
+	}
+	if registry.resolve(&CqResponse{Echo: "unknown"}) {
+		t.Error("resolve() = true for an unknown echo")
+	}
+}
+
+func TestRegistryCancel(t *testing.T) {
+	registry := newResponseRegistry()
+	registry.register("1")
+	registry.cancel("1")
+
+	if registry.resolve(&CqResponse{Echo: "1"}) {
+		t.Error("resolve() = true after cancel")
+	}
+}
+
+func TestRegistryFailAll(t *testing.T) {
+	registry := newResponseRegistry()
+	first := registry.register("1")
+	second := registry.register("2")
+
+	registry.failAll()
+
+	for _, resChan := range []<-chan *CqResponse{first, second} {
+		if _, ok := <-resChan; ok {
+			t.Error("channel not closed by failAll")
+		}
+	}
+	if registry.resolve(&CqResponse{Echo: "1"}) {
+		t.Error("resolve() = true after failAll")
+	}
+}
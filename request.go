@@ -0,0 +1,126 @@
+package hareru_cq
+
+import (
+	"context"
+	"encoding/json"
+)
+
+const (
+	AddRequestType    = "add"    // GroupRequest.SubType 加群请求
+	InviteRequestType = "invite" // GroupRequest.SubType 邀请 Bot 入群
+)
+
+// Request 请求事件, 为 *FriendRequest 或 *GroupRequest
+type Request interface {
+	Approve(remark string) error // 同意请求
+	Reject(reason string) error  // 拒绝请求
+}
+
+// FriendRequest 加好友请求
+type FriendRequest struct {
+	Time    int64  `json:"time"`
+	UserId  int64  `json:"user_id"`
+	Comment string `json:"comment"` // 验证信息
+	Flag    string `json:"flag"`    // 处理请求时使用
+
+	Bot *Bot `json:"-"`
+}
+
+// GroupRequest 加群请求 / 邀请
+type GroupRequest struct {
+	Time    int64  `json:"time"`
+	SubType string `json:"sub_type"` // AddRequestType 或 InviteRequestType
+	GroupId int64  `json:"group_id"`
+	UserId  int64  `json:"user_id"`
+	Comment string `json:"comment"` // 验证信息
+	Flag    string `json:"flag"`    // 处理请求时使用
+
+	Bot *Bot `json:"-"`
+}
+
+// Approve 同意好友请求
+// remark string 好友备注
+func (req *FriendRequest) Approve(remark string) error {
+	return req.Bot.SetFriendAddRequest(req.Flag, true, remark)
+}
+
+// Reject 拒绝好友请求, 好友请求不支持拒绝理由, reason 将被忽略
+func (req *FriendRequest) Reject(reason string) error {
+	return req.Bot.SetFriendAddRequest(req.Flag, false, "")
+}
+
+// Approve 同意加群请求或邀请, 加群请求不支持备注, remark 将被忽略
+func (req *GroupRequest) Approve(remark string) error {
+	return req.Bot.SetGroupAddRequest(req.Flag, req.SubType, true, "")
+}
+
+// Reject 拒绝加群请求或邀请
+// reason string 拒绝理由
+func (req *GroupRequest) Reject(reason string) error {
+	return req.Bot.SetGroupAddRequest(req.Flag, req.SubType, false, reason)
+}
+
+// IsInvite 是否为邀请 Bot 入群
+func (req *GroupRequest) IsInvite() bool {
+	return req.SubType == InviteRequestType
+}
+
+// Request 由请求事件构建 *FriendRequest 或 *GroupRequest, 非请求事件返回 nil
+func (update *Update) Request() Request {
+	if update.Event.Type != "request" {
+		return nil
+	}
+
+	var req Request
+	switch update.Event.Get("request_type").String() {
+	case "friend":
+		req = &FriendRequest{Bot: update.Bot}
+	case "group":
+		req = &GroupRequest{Bot: update.Bot}
+	default:
+		return nil
+	}
+
+	err := json.Unmarshal([]byte(update.Event.Json.Raw), req)
+	if err != nil {
+		return nil
+	}
+
+	return req
+}
+
+// SetFriendAddRequest 处理加好友请求
+// flag string 请求事件中的 flag
+// remark string 同意时的好友备注
+func (bot *Bot) SetFriendAddRequest(flag string, approve bool, remark string) error {
+	return bot.SetFriendAddRequestCtx(context.Background(), flag, approve, remark)
+}
+
+// SetFriendAddRequestCtx 处理加好友请求
+func (bot *Bot) SetFriendAddRequestCtx(ctx context.Context, flag string, approve bool, remark string) error {
+	_, err := bot.call(ctx, "set_friend_add_request", map[string]interface{}{
+		"flag":    flag,
+		"approve": approve,
+		"remark":  remark,
+	})
+	return err
+}
+
+// SetGroupAddRequest 处理加群请求 / 邀请
+// flag string 请求事件中的 flag
+// subType string 请求事件中的 sub_type (add 或 invite)
+// reason string 拒绝理由
+func (bot *Bot) SetGroupAddRequest(flag string, subType string, approve bool, reason string) error {
+	return bot.SetGroupAddRequestCtx(context.Background(), flag, subType, approve, reason)
+}
+
+// SetGroupAddRequestCtx 处理加群请求 / 邀请
+func (bot *Bot) SetGroupAddRequestCtx(ctx context.Context, flag string, subType string, approve bool, reason string) error {
+	_, err := bot.call(ctx, "set_group_add_request", map[string]interface{}{
+		"flag":     flag,
+		"sub_type": subType,
+		"approve":  approve,
+		"reason":   reason,
+	})
+	return err
+}
@@ -0,0 +1,124 @@
+package hareru_cq
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gorilla/websocket"
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	UniversalRole = "Universal" // X-Client-Role
+	ApiRole       = "API"
+	EventRole     = "Event"
+)
+
+var upgrader = websocket.Upgrader{
+	CheckOrigin: func(r *http.Request) bool {
+		return true
+	},
+}
+
+// listen 启动反向 WebSocket 服务并等待 cqhttp 连接
+func (c *Client) listen() error {
+	err := c.startServer(http.HandlerFunc(c.serveReverse))
+	if err != nil {
+		return err
+	}
+
+	log.Infoln("反向 WebSocket 服务已启动, 等待 cqHttp 连接:", c.ListenAddr)
+
+	<-c.ready
+
+	log.Infoln("cqHttp 连接成功")
+
+	return nil
+}
+
+// serveReverse 接受 cqhttp 的反向 WebSocket 连接
+func (c *Client) serveReverse(w http.ResponseWriter, r *http.Request) {
+	if !c.authorize(w, r, true) {
+		return
+	}
+
+	role := reverseRole(r)
+	if role == "" {
+		http.Error(w, "unknown X-Client-Role", http.StatusBadRequest)
+		return
+	}
+
+	selfId, _ := strconv.ParseInt(r.Header.Get("X-Self-ID"), 10, 64)
+
+	conn, err := upgrader.Upgrade(w, r, nil)
+	if err != nil {
+		log.Error("反向 WebSocket 握手失败:", err)
+		return
+	}
+
+	log.Infof("cqHttp(%d) 已连接: %s", selfId, role)
+
+	c.addConn(role, selfId, conn)
+}
+
+// reverseRole 由 X-Client-Role 或请求路径确定连接角色
+func reverseRole(r *http.Request) string {
+	role := r.Header.Get("X-Client-Role")
+	if role == "" {
+		role = r.URL.Path[strings.LastIndex(r.URL.Path, "/")+1:]
+	}
+
+	switch strings.ToLower(role) {
+	case "universal", "ws", "":
+		return UniversalRole
+	case "api":
+		return ApiRole
+	case "event":
+		return EventRole
+	}
+
+	return ""
+}
+
+// addConn 登记反向连接, 同一角色的新连接会替换旧连接
+func (c *Client) addConn(role string, selfId int64, conn *websocket.Conn) {
+	c.connLock.Lock()
+	defer c.connLock.Unlock()
+
+	c.SelfId = selfId
+
+	switch role {
+	case UniversalRole:
+		c.replaceConn(&c.ActConn, conn)
+		c.replaceConn(&c.EventConn, conn)
+	case ApiRole:
+		c.replaceConn(&c.ActConn, conn)
+	case EventRole:
+		c.replaceConn(&c.EventConn, conn)
+	}
+
+	go c.readLoop(conn)
+
+	if c.ActConn == nil || c.EventConn == nil || c.connected {
+		return
+	}
+
+	c.connected = true
+
+	select {
+	case <-c.ready:
+		log.Infoln("cqHttp 重连成功")
+		c.notifyConnect()
+	default:
+		close(c.ready)
+	}
+}
+
+// replaceConn 关闭旧连接并替换
+func (c *Client) replaceConn(slot **websocket.Conn, conn *websocket.Conn) {
+	if *slot != nil && *slot != conn {
+		_ = (*slot).Close()
+	}
+	*slot = conn
+}
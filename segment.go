@@ -0,0 +1,258 @@
+package hareru_cq
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/tidwall/gjson"
+)
+
+const (
+	TextSegment     = "text" // 消息段类型
+	FaceSegment     = "face"
+	ImageSegment    = "image"
+	RecordSegment   = "record"
+	VideoSegment    = "video"
+	AtSegment       = "at"
+	RpsSegment      = "rps"
+	DiceSegment     = "dice"
+	ShakeSegment    = "shake"
+	PokeSegment     = "poke"
+	ShareSegment    = "share"
+	ContactSegment  = "contact"
+	LocationSegment = "location"
+	MusicSegment    = "music"
+	ReplySegment    = "reply"
+	ForwardSegment  = "forward"
+	NodeSegment     = "node"
+	XmlSegment      = "xml"
+	JsonSegment     = "json"
+)
+
+// Segment 消息段
+// Data 的值通常为 string, 由数组格式解析时数字为 json.Number, 合并转发节点的 content 为 []any
+type Segment struct {
+	Type string         `json:"type"`
+	Data map[string]any `json:"data"`
+}
+
+// MessageChain 消息链
+type MessageChain []Segment
+
+// Get 取消息段参数
+func (seg Segment) Get(key string) string {
+	switch value := seg.Data[key].(type) {
+	case nil:
+		return ""
+	case string:
+		return value
+	default:
+		return fmt.Sprint(value)
+	}
+}
+
+// Int 取整数型消息段参数, 无法解析时返回 0
+func (seg Segment) Int(key string) int64 {
+	value, _ := strconv.ParseInt(seg.Get(key), 10, 64)
+	return value
+}
+
+// with 返回设置了参数的消息段副本
+func (seg Segment) with(key string, value any) Segment {
+	data := make(map[string]any, len(seg.Data)+1)
+	for k, v := range seg.Data {
+		data[k] = v
+	}
+	data[key] = value
+
+	return Segment{Type: seg.Type, Data: data}
+}
+
+// UnmarshalJSON 解析数组格式的消息段, 数字保留为 json.Number 以免丢失精度
+func (seg *Segment) UnmarshalJSON(data []byte) error {
+	var raw struct {
+		Type string         `json:"type"`
+		Data map[string]any `json:"data"`
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+
+	err := decoder.Decode(&raw)
+	if err != nil {
+		return err
+	}
+
+	seg.Type = raw.Type
+	seg.Data = raw.Data
+	if seg.Data == nil {
+		seg.Data = map[string]any{}
+	}
+
+	return nil
+}
+
+// PlainText 拼接所有文本消息段
+func (chain MessageChain) PlainText() string {
+	var builder strings.Builder
+	for _, seg := range chain {
+		if seg.Type == TextSegment {
+			builder.WriteString(seg.Get("text"))
+		}
+	}
+	return builder.String()
+}
+
+// Segments 取指定类型的消息段
+func (chain MessageChain) Segments(segType string) []Segment {
+	segments := make([]Segment, 0)
+	for _, seg := range chain {
+		if seg.Type == segType {
+			segments = append(segments, seg)
+		}
+	}
+	return segments
+}
+
+// Has 是否包含指定类型的消息段
+func (chain MessageChain) Has(segType string) bool {
+	for _, seg := range chain {
+		if seg.Type == segType {
+			return true
+		}
+	}
+	return false
+}
+
+// Images 取图片消息段
+func (chain MessageChain) Images() []Segment {
+	return chain.Segments(ImageSegment)
+}
+
+// Mentions 取被 @ 的用户, 不含 @全体成员
+func (chain MessageChain) Mentions() []int64 {
+	mentions := make([]int64, 0)
+	for _, seg := range chain.Segments(AtSegment) {
+		if userId := seg.Int("qq"); userId != 0 {
+			mentions = append(mentions, userId)
+		}
+	}
+	return mentions
+}
+
+// Mentioned 是否 @ 了指定用户
+func (chain MessageChain) Mentioned(userId int64) bool {
+	for _, mention := range chain.Mentions() {
+		if mention == userId {
+			return true
+		}
+	}
+	return false
+}
+
+// MentionsAll 是否 @全体成员
+func (chain MessageChain) MentionsAll() bool {
+	for _, seg := range chain.Segments(AtSegment) {
+		if seg.Get("qq") == "all" {
+			return true
+		}
+	}
+	return false
+}
+
+// ReplyId 取回复的消息 ID
+func (chain MessageChain) ReplyId() (int64, bool) {
+	for _, seg := range chain.Segments(ReplySegment) {
+		id, err := strconv.ParseInt(seg.Get("id"), 10, 64)
+		if err == nil {
+			return id, true
+		}
+	}
+	return 0, false
+}
+
+// ParseMessage 解析消息, 支持 CQ 码字符串与数组格式
+func ParseMessage(message gjson.Result) MessageChain {
+	if message.IsArray() {
+		chain, err := ParseMessageArray([]byte(message.Raw))
+		if err == nil {
+			return chain
+		}
+	}
+
+	return ParseCQString(message.String())
+}
+
+// ParseMessageArray 解析数组格式的消息
+func ParseMessageArray(data []byte) (MessageChain, error) {
+	chain := make(MessageChain, 0)
+	err := json.Unmarshal(data, &chain)
+	if err != nil {
+		return nil, err
+	}
+	return chain, nil
+}
+
+// ParseCQString 解析 CQ 码字符串
+func ParseCQString(message string) MessageChain {
+	chain := make(MessageChain, 0)
+
+	for len(message) > 0 {
+		start := strings.Index(message, "[CQ:")
+		if start < 0 {
+			chain = chain.appendText(message)
+			break
+		}
+
+		end := strings.IndexByte(message[start:], ']')
+		if end < 0 {
+			chain = chain.appendText(message)
+			break
+		}
+		end += start
+
+		chain = chain.appendText(message[:start])
+		chain = append(chain, parseCQCode(message[start+len("[CQ:"):end]))
+		message = message[end+1:]
+	}
+
+	return chain
+}
+
+// parseCQCode 解析 CQ 码内容, 如 image,file=a.jpg
+func parseCQCode(code string) Segment {
+	parts := strings.Split(code, ",")
+
+	seg := Segment{
+		Type: parts[0],
+		Data: make(map[string]any, len(parts)-1),
+	}
+
+	for _, part := range parts[1:] {
+		key, value, _ := strings.Cut(part, "=")
+		seg.Data[key] = unescapeCQ(value)
+	}
+
+	return seg
+}
+
+// appendText 追加文本消息段
+func (chain MessageChain) appendText(text string) MessageChain {
+	if text == "" {
+		return chain
+	}
+	return append(chain, Segment{
+		Type: TextSegment,
+		Data: map[string]any{"text": unescapeCQ(text)},
+	})
+}
+
+var cqUnescaper = strings.NewReplacer("&#91;", "[", "&#93;", "]", "&#44;", ",", "&amp;", "&")
+
+// unescapeCQ CQ 码反转义
+func unescapeCQ(text string) string {
+	return cqUnescaper.Replace(text)
+}
@@ -0,0 +1,119 @@
+package hareru_cq
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+
+	"github.com/tidwall/gjson"
+)
+
+func text(s string) Segment {
+	return Segment{Type: TextSegment, Data: map[string]any{"text": s}}
+}
+
+func TestParseCQString(t *testing.T) {
+	tests := []struct {
+		name    string
+		message string
+		want    MessageChain
+	}{
+		{"empty", "", MessageChain{}},
+		{"plain text", "hello", MessageChain{text("hello")}},
+		{"text around codes", "a[CQ:face,id=1]b[CQ:at,qq=2]", MessageChain{
+			text("a"),
+			{Type: FaceSegment, Data: map[string]any{"id": "1"}},
+			text("b"),
+			{Type: AtSegment, Data: map[string]any{"qq": "2"}},
+		}},
+		{"adjacent codes", "[CQ:reply,id=5][CQ:shake]", MessageChain{
+			{Type: ReplySegment, Data: map[string]any{"id": "5"}},
+			{Type: ShakeSegment, Data: map[string]any{}},
+		}},
+		{"unterminated code", "hi [CQ:image,file=a.png", MessageChain{text("hi [CQ:image,file=a.png")}},
+		{"unterminated after code", "[CQ:face,id=1] x[CQ:at", MessageChain{
+			{Type: FaceSegment, Data: map[string]any{"id": "1"}},
+			text(" x[CQ:at"),
+		}},
+		{"escaped text", "&#91;not code&#93; &amp; more", MessageChain{text("[not code] & more")}},
+		{"escaped param", "[CQ:share,url=a&#44;b,title=x&amp;y]", MessageChain{
+			{Type: ShareSegment, Data: map[string]any{"url": "a,b", "title": "x&y"}},
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ParseCQString(tt.message); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ParseCQString(%q) = %v, want %v", tt.message, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseMessageArray(t *testing.T) {
+	data := `[{"type":"text","data":{"text":"hi"}},{"type":"at","data":{"qq":1234567890123}},{"type":"reply","data":{"id":-42}},{"type":"shake"}]`
+
+	chain, err := ParseMessageArray([]byte(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(chain) != 4 {
+		t.Fatalf("len = %d, want 4", len(chain))
+	}
+
+	if qq, ok := chain[1].Data["qq"].(json.Number); !ok || qq.String() != "1234567890123" {
+		t.Errorf("qq = %#v, want json.Number 1234567890123", chain[1].Data["qq"])
+	}
+	if got := chain[1].Int("qq"); got != 1234567890123 {
+		t.Errorf("Int(qq) = %d", got)
+	}
+	if got := chain[2].Get("id"); got != "-42" {
+		t.Errorf("Get(id) = %q, want -42", got)
+	}
+	if chain[3].Data == nil {
+		t.Error("missing data not initialised")
+	}
+
+	if _, err := ParseMessageArray([]byte(`{"type":"text"}`)); err == nil {
+		t.Error("expected error for non-array input")
+	}
+}
+
+func TestParseMessage(t *testing.T) {
+	array := gjson.Parse(`{"message":[{"type":"face","data":{"id":"1"}}]}`).Get("message")
+	str := gjson.Parse(`{"message":"[CQ:face,id=1]"}`).Get("message")
+
+	if a, s := ParseMessage(array), ParseMessage(str); !reflect.DeepEqual(a, s) {
+		t.Errorf("array %v != string %v", a, s)
+	}
+}
+
+func TestMessageChainQueries(t *testing.T) {
+	chain := ParseCQString("[CQ:reply,id=77][CQ:at,qq=10] hi [CQ:at,qq=all][CQ:at,qq=20][CQ:image,file=a.png]")
+
+	if id, ok := chain.ReplyId(); !ok || id != 77 {
+		t.Errorf("ReplyId() = %d, %v", id, ok)
+	}
+	if _, ok := ParseCQString("[CQ:reply,id=x]").ReplyId(); ok {
+		t.Error("ReplyId() ok for non-numeric id")
+	}
+	if _, ok := ParseCQString("hi").ReplyId(); ok {
+		t.Error("ReplyId() ok without reply")
+	}
+
+	if got := chain.Mentions(); !reflect.DeepEqual(got, []int64{10, 20}) {
+		t.Errorf("Mentions() = %v, want [10 20]", got)
+	}
+	if !chain.Mentioned(20) || chain.Mentioned(30) {
+		t.Error("Mentioned() mismatch")
+	}
+	if !chain.MentionsAll() {
+		t.Error("MentionsAll() = false")
+	}
+	if got := chain.PlainText(); got != " hi " {
+		t.Errorf("PlainText() = %q", got)
+	}
+	if !chain.Has(ImageSegment) || len(chain.Images()) != 1 || chain.Has(VideoSegment) {
+		t.Error("Has()/Images() mismatch")
+	}
+}
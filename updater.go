@@ -17,6 +17,8 @@ type Update struct {
 	UpdateId int64
 	Bot      *Bot
 	Event    *Event
+
+	quickReply chan *QuickOperation
 }
 
 func (updater *Updater) Init() error {
@@ -38,18 +40,17 @@ func (updater *Updater) Init() error {
 }
 
 func (updater *Updater) startPull() {
-	for {
-		_, message, err := updater.Bot.Client.EventConn.ReadMessage()
-		if err != nil {
-			log.Fatal("与 cqHttp 的连接出错:", err)
-			return
-		}
+	for frame := range updater.Bot.Client.events {
+		message := frame.data
 
 		event := &Event{}
-		err = json.Unmarshal(message, &event)
+		err := json.Unmarshal(message, &event)
 		if err != nil {
-			log.Fatal("cqHttp 事件获取失败:", err)
-			return
+			log.Error("cqHttp 事件解析失败:", err)
+			if frame.reply != nil {
+				frame.reply <- nil
+			}
+			continue
 		}
 
 		event.Json = gjson.Parse(string(message))
@@ -58,6 +59,8 @@ func (updater *Updater) startPull() {
 			UpdateId: event.Time,
 			Bot:      updater.Bot,
 			Event:    event,
+
+			quickReply: frame.reply,
 		}
 
 		//log.Println("收到事件:", event.Json.Raw)
@@ -1,19 +1,25 @@
 package hareru_cq
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"io"
 	"net/http"
 )
 
-func getHttpRes(url string) ([]byte, error) {
+func getHttpRes(ctx context.Context, url string) ([]byte, error) {
+	request, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+
 	client := http.Client{}
-	response, err := client.Get(url)
-	defer response.Body.Close()
+	response, err := client.Do(request)
 	if err != nil {
 		return nil, err
 	}
+	defer response.Body.Close()
 
 	if response.StatusCode != http.StatusOK {
 		return nil, errors.New(fmt.Sprintf("http request error: %d %s", response.StatusCode, response.Status))